@@ -0,0 +1,114 @@
+package tigo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"path"
+	"strings"
+)
+
+// AssetManifest maps logical asset names, such as "css/site.css", to fingerprinted URLs,
+// such as "/assets/css/site.3f2a9c1d.css", so that assets can be cached forever and are
+// refetched by clients whenever their content changes.
+type AssetManifest struct {
+	prefix  string
+	assets  map[string]string //logical name => fingerprinted name
+	reverse map[string]string //fingerprinted name => logical name, for assets hashed at startup
+}
+
+// NewAssetManifest builds a manifest at startup by hashing every file in fsys, except hidden files.
+// The URLs are prefixed with urlPrefix, which should be the route path of the static handler
+// serving fsys, e.g. "/assets". That handler must use the manifest in its StaticConfig so that
+// fingerprinted URLs are served from the original files.
+func NewAssetManifest(fsys fs.FS, urlPrefix string) (*AssetManifest, error) {
+	m := &AssetManifest{
+		prefix:  strings.TrimSuffix(urlPrefix, "/"),
+		assets:  make(map[string]string),
+		reverse: make(map[string]string),
+	}
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name != "." && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		f, err := fsys.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		hash := sha256.New()
+		if _, err := io.Copy(hash, f); err != nil {
+			return err
+		}
+		fingerprinted := fingerprintName(name, hex.EncodeToString(hash.Sum(nil))[:10])
+		m.assets[name] = fingerprinted
+		m.reverse[fingerprinted] = name
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// LoadAssetManifest reads a manifest created at build time, which is a JSON object mapping
+// logical names to fingerprinted names, e.g. {"css/site.css": "css/site.3f2a9c1d.css"}.
+// The fingerprinted files are expected to exist under those names.
+func LoadAssetManifest(file string, urlPrefix string) (*AssetManifest, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	m := &AssetManifest{prefix: strings.TrimSuffix(urlPrefix, "/")}
+	if err := json.Unmarshal(data, &m.assets); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// URL returns the fingerprinted URL of the named asset.
+// If the asset is not in the manifest, or the manifest is nil, the unfingerprinted URL is returned.
+func (m *AssetManifest) URL(name string) string {
+	name = strings.TrimPrefix(name, "/")
+	if m == nil {
+		return "/" + name
+	}
+	if fingerprinted, ok := m.assets[name]; ok {
+		name = fingerprinted
+	}
+	return m.prefix + "/" + name
+}
+
+// WriteJSON writes the manifest in the format read by LoadAssetManifest.
+func (m *AssetManifest) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(m.assets)
+}
+
+// original returns the logical name of a fingerprinted name created at startup,
+// or an empty string if name is not such a fingerprinted name.
+func (m *AssetManifest) original(name string) string {
+	if m == nil {
+		return ""
+	}
+	return m.reverse[name]
+}
+
+// fingerprintName inserts the hash before the extension of name, e.g. "css/site.3f2a9c1d.css".
+func fingerprintName(name, hash string) string {
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + hash + ext
+}
@@ -0,0 +1,64 @@
+package tigo
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssetManifest(t *testing.T) {
+	fsys := fstest.MapFS{
+		"css/site.css": {Data: []byte("body{}")},
+		"app.js":       {Data: []byte("app")},
+		".env":         {Data: []byte("SECRET=1")},
+	}
+	m, err := NewAssetManifest(fsys, "/assets/")
+	if !assert.Nil(t, err) {
+		return
+	}
+	cssURL := m.URL("css/site.css")
+	assert.Regexp(t, `^/assets/css/site\.[0-9a-f]{10}\.css$`, cssURL)
+	assert.Equal(t, cssURL, m.URL("/css/site.css"))
+	assert.Equal(t, "/assets/unknown.png", m.URL("unknown.png"))
+	assert.Equal(t, "/assets/.env", m.URL(".env"))
+
+	var nilManifest *AssetManifest
+	assert.Equal(t, "/css/site.css", nilManifest.URL("css/site.css"))
+
+	router := New()
+	router.StaticFS("/assets/*", fsys, StaticConfig{Manifest: m, CacheRules: []CacheRule{ImmutableCacheRule}})
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", cssURL, nil)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, "body{}", res.Body.String())
+	assert.Equal(t, "public, max-age=31536000, immutable", res.Header().Get("Cache-Control"))
+
+	res = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/assets/css/site.css", nil)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, "body{}", res.Body.String())
+	assert.Equal(t, "", res.Header().Get("Cache-Control"))
+
+	dir, _ := ioutil.TempDir("", "tigo")
+	defer os.RemoveAll(dir)
+	var buf bytes.Buffer
+	assert.Nil(t, m.WriteJSON(&buf))
+	ioutil.WriteFile(filepath.Join(dir, "manifest.json"), buf.Bytes(), 0644)
+	loaded, err := LoadAssetManifest(filepath.Join(dir, "manifest.json"), "/static")
+	if assert.Nil(t, err) {
+		assert.Equal(t, "/static"+cssURL[len("/assets"):], loaded.URL("css/site.css"))
+	}
+
+	ioutil.WriteFile(filepath.Join(dir, "index.html"), []byte(`<link href="{{asset "css/site.css"}}">`), 0644)
+	render := NewViewRender(ViewRenderConfig{Root: dir, Extension: ".html", Assets: m})
+	buf.Reset()
+	assert.Nil(t, render.RenderFile(&buf, "index", nil))
+	assert.Equal(t, `<link href="`+cssURL+`">`, buf.String())
+}
@@ -0,0 +1,130 @@
+package tigo
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// UserKey is the key used to store and retrieve the authenticated identity in the Context.
+const UserKey = "User"
+
+// DefaultRealm is the realm used in the WWW-Authenticate header when none is specified.
+var DefaultRealm = "API"
+
+// Identity represents an authenticated principal, such as a user record or a set of JWT claims.
+type Identity interface{}
+
+// BasicAuthFunc validates the username and password sent with HTTP basic authentication.
+// It should return the identity of the authenticated user, or an error if the credentials are invalid.
+type BasicAuthFunc func(c *Context, username, password string) (Identity, error)
+
+// BearerAuthFunc validates the token sent in the "Authorization: Bearer" header.
+// It should return the identity associated with the token, or an error if the token is invalid.
+type BearerAuthFunc func(c *Context, token string) (Identity, error)
+
+// User returns the identity stored in the context by one of the authentication handlers.
+// Nil is returned if the request has not been authenticated.
+func (c *Context) User() Identity {
+	return c.Get(UserKey)
+}
+
+// BasicAuth returns a handler that performs HTTP basic authentication.
+// The username and password are validated by fn. If they are valid, the returned identity is stored
+// in the context under UserKey. Otherwise a 401 HTTP error is returned together with a
+// WWW-Authenticate header using the given realm (DefaultRealm if not given).
+// The error returned by fn is only sent to the client if it is a StatusError, as its public message.
+func BasicAuth(fn BasicAuthFunc, realm ...string) Handler {
+	name := DefaultRealm
+	if len(realm) > 0 {
+		name = realm[0]
+	}
+	return func(c *Context) error {
+		var err error
+		if username, password, ok := c.Request.BasicAuth(); ok {
+			var identity Identity
+			if identity, err = fn(c, username, password); err == nil {
+				c.Set(UserKey, identity)
+				return nil
+			}
+		}
+		c.Response.Header().Set("WWW-Authenticate", "Basic realm="+quoteString(name)+`, charset="UTF-8"`)
+		return unauthorizedError(err)
+	}
+}
+
+// BearerAuth returns a handler that performs bearer token authentication as described in RFC 6750.
+// The token extracted from the "Authorization" header is validated by fn. If it is valid, the returned
+// identity is stored in the context under UserKey. Otherwise a 401 HTTP error is returned together with
+// a WWW-Authenticate header using the given realm (DefaultRealm if not given).
+// The error returned by fn is only sent to the client if it is a StatusError, as its public message,
+// which is also the error description of the header.
+func BearerAuth(fn BearerAuthFunc, realm ...string) Handler {
+	name := DefaultRealm
+	if len(realm) > 0 {
+		name = realm[0]
+	}
+	return func(c *Context) error {
+		token := BearerToken(c.Request)
+		if token == "" {
+			c.Response.Header().Set("WWW-Authenticate", "Bearer realm="+quoteString(name))
+			return NewHTTPError(http.StatusUnauthorized)
+		}
+		identity, err := fn(c, token)
+		if err != nil {
+			header := "Bearer realm=" + quoteString(name) + `, error="invalid_token"`
+			var statusError *StatusError
+			if errors.As(err, &statusError) {
+				header += ", error_description=" + quoteString(statusError.PublicMessage())
+			}
+			c.Response.Header().Set("WWW-Authenticate", header)
+			return unauthorizedError(err)
+		}
+		c.Set(UserKey, identity)
+		return nil
+	}
+}
+
+// BearerToken returns the token in the "Authorization: Bearer" header of the request.
+// An empty string is returned if the header is missing or uses a different scheme.
+func BearerToken(req *http.Request) string {
+	auth := req.Header.Get("Authorization")
+	if len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+		return strings.TrimSpace(auth[7:])
+	}
+	return ""
+}
+
+// unauthorizedError returns a 401 HTTP error caused by the error of an authentication function.
+// Only the public message of a StatusError is sent to the client, the other errors are only logged.
+func unauthorizedError(err error) error {
+	message := http.StatusText(http.StatusUnauthorized)
+	var statusError *StatusError
+	if errors.As(err, &statusError) {
+		message = statusError.PublicMessage()
+	}
+	if err == nil {
+		return NewHTTPError(http.StatusUnauthorized, message)
+	}
+	return NewStatusError(http.StatusUnauthorized, message).WithCause(err)
+}
+
+// quoteString returns s as a quoted-string of RFC 7230, escaping quotes and backslashes
+// and dropping the control characters which cannot be sent in a header.
+func quoteString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 && c != '\t' || c == 0x7f:
+			// not allowed in a header
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -5,25 +5,34 @@
 package tigo
 
 import (
+	"bytes"
+	"io"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
 	"fmt"
-	"encoding/json"
 	"time"
 	"io/ioutil"
 )
 
 // Context represents the contextual data and environment while processing an incoming HTTP request.
 type Context struct {
-	Request  *http.Request          // the current request
-	Response http.ResponseWriter    // the response writer
-	router   *Router
-	pnames   []string               // list of route parameter names
-	pvalues  []string               // list of parameter values corresponding to pnames
-	data     map[string]interface{} // data items managed by Get and Set
-	index    int                    // the index of the currently executing handler in handlers
-	handlers []Handler              // the handlers associated with the current route
-	writer   DataWriter
+	Request    *http.Request          // the current request
+	Response   http.ResponseWriter    // the response writer
+	response   Response               // the response tracking the status and size, wrapping the writer of the request
+	router     *Router
+	route      *Route                 // the route matching the current request
+	group      *RouteGroup            // the group of the route, or the group containing the path if no route matches
+	pnames     []string               // list of route parameter names
+	pvalues    []string               // list of parameter values corresponding to pnames
+	data       map[string]interface{} // data items managed by Get and Set
+	index      int                    // the index of the currently executing handler in handlers
+	handlers   []Handler              // the handlers associated with the current route
+	writer     DataWriter
+	formParsed bool                   // whether the form of the request has been parsed
+	formErr    error                  // the error of parsing the form
+	query      url.Values             // the query parameters of the request, parsed once
 }
 
 // NewContext creates a new Context object with the given response, request, and the handlers.
@@ -39,6 +48,12 @@ func (c *Context) Router() *Router {
 	return c.router
 }
 
+// Route returns the route matching the current request.
+// Nil is returned if no route matches the request, e.g. when the NotFound handlers are invoked.
+func (c *Context) Route() *Route {
+	return c.route
+}
+
 // Param returns the named parameter value that is found in the URL path matching the current route.
 // If the named parameter cannot be found, an empty string will be returned.
 func (c *Context) Param(name string) string {
@@ -80,7 +95,7 @@ func (c *Context) Set(name string, value interface{}) {
 // Query returns the first value for the named component of the URL query parameters.
 // If key is not present, it returns the specified default value or an empty string.
 func (c *Context) Query(name string, defaultValue ...string) string {
-	if vs, _ := c.Request.URL.Query()[name]; len(vs) > 0 {
+	if vs := c.QueryValues()[name]; len(vs) > 0 {
 		return vs[0]
 	}
 	if len(defaultValue) > 0 {
@@ -105,7 +120,7 @@ func (c *Context) RequestBody() ([]byte, error) {
 // If key is not present, it returns the specified default value or an empty string.
 func (c *Context) Form(key string, defaultValue ...string) string {
 	r := c.Request
-	r.ParseMultipartForm(32 << 20)
+	c.parseForm()
 	if vs := r.Form[key]; len(vs) > 0 {
 		return vs[0]
 	}
@@ -120,7 +135,7 @@ func (c *Context) Form(key string, defaultValue ...string) string {
 // If key is not present, it returns the specified default value or an empty string.
 func (c *Context) PostForm(key string, defaultValue ...string) string {
 	r := c.Request
-	r.ParseMultipartForm(32 << 20)
+	c.parseForm()
 	if vs := r.PostForm[key]; len(vs) > 0 {
 		return vs[0]
 	}
@@ -170,14 +185,20 @@ func (c *Context) URL(route string, pairs ...interface{}) string {
 // If there is no match or if the request is a GET request, it will use DefaultFormDataReader
 // to read the request data.
 func (c *Context) Read(data interface{}) error {
+	reader := DefaultFormDataReader
 	if c.Request.Method != "GET" {
 		t := getContentType(c.Request)
-		if reader, ok := DataReaders[t]; ok {
-			return reader.Read(c.Request, data)
+		if r, ok := DataReaders[t]; ok {
+			reader = r
 		}
 	}
-
-	return DefaultFormDataReader.Read(c.Request, data)
+	// forms are parsed according to the upload config
+	if _, ok := reader.(*FormDataReader); ok {
+		if err := c.parseForm(); err != nil {
+			return err
+		}
+	}
+	return reader.Read(c.Request, data)
 }
 
 // Write writes the given data of arbitrary type to the response.
@@ -194,13 +215,75 @@ func (c *Context) SetDataWriter(writer DataWriter) {
 
 // init sets the request and response of the context and resets all other properties.
 func (c *Context) init(response http.ResponseWriter, request *http.Request) {
-	c.Response = response
+	c.response.reset(response)
+	if response != nil {
+		c.Response = &c.response
+	} else {
+		c.Response = nil
+	}
 	c.Request = request
+	c.route = nil
+	c.group = nil
 	c.data = nil
 	c.index = -1
+	c.formParsed = false
+	c.formErr = nil
+	c.query = nil
 	c.writer = DefaultDataWriter
 }
 
+// StatusCode returns the status code of the response, i.e. the status code sent if the response is committed,
+// or else the status code set with Status, 200 by default.
+func (c *Context) StatusCode() int {
+	return c.response.Status()
+}
+
+// Status sets the status code of the response written next, e.g. by JSON, Text or Render, and returns the context
+// so that the response can be written fluently, e.g. c.Status(http.StatusCreated).JSON(user).
+func (c *Context) Status(code int) *Context {
+	c.response.setStatus(code)
+	return c
+}
+
+// Header sets a header of the response and returns the context, e.g. c.Header("Location", location).JSON(user).
+func (c *Context) Header(key, value string) *Context {
+	c.Response.Header().Set(key, value)
+	return c
+}
+
+// statusOf returns the status code of the response, or of err if the response is not committed,
+// since err is then sent by the error handler after being mapped by the group of the request.
+func (c *Context) statusOf(err error) int {
+	if c.Written() {
+		return c.StatusCode()
+	}
+	if err != nil && c.group != nil {
+		err = c.group.mapError(err)
+	}
+	return statusCodeOf(err, c.StatusCode())
+}
+
+// Size returns the number of bytes of the response body written so far.
+func (c *Context) Size() int64 {
+	return c.response.Size()
+}
+
+// Written returns whether the response is committed, i.e. whether its status code and headers have been sent.
+// After that, errors can no longer be sent to the client.
+func (c *Context) Written() bool {
+	return c.response.Committed()
+}
+
+// BeforeWrite registers a function called just before the response is committed, e.g. to set headers.
+func (c *Context) BeforeWrite(fn func()) {
+	c.response.beforeWrite = append(c.response.beforeWrite, fn)
+}
+
+// AfterWrite registers a function called with the data written each time the response body is written.
+func (c *Context) AfterWrite(fn func(data []byte)) {
+	c.response.afterWrite = append(c.response.afterWrite, fn)
+}
+
 // writeWithStatusCode writes the given data of arbitrary type to the response.
 // The method calls the Serialize() method to convert the data into a byte array and then writes
 // the byte array to the response.
@@ -213,13 +296,62 @@ func (c *Context) writeWithContentType(contentType string, bytes []byte) error {
 	return nil
 }
 
+// writeData writes data to the response with the given data writer.
+func (c *Context) writeData(writer DataWriter, data interface{}) error {
+	writer.SetHeader(c.Response)
+	return writer.Write(c.Response, data)
+}
+
 // JSON writes json values to the response.
-func (c *Context) JSON(data interface{}) (err error) {
-	var bytes []byte
-	if bytes, err = json.Marshal(data); err == nil {
-		err = c.writeWithContentType("application/json; charset=utf-8", bytes)
+// The JSON is indented if the router is in debug mode.
+func (c *Context) JSON(data interface{}) error {
+	if c.router != nil && c.router.Debug {
+		return c.IndentedJSON(data)
+	}
+	return c.writeData(&JSONDataWriter{}, data)
+}
+
+// IndentedJSON writes json values to the response, indented for readability.
+func (c *Context) IndentedJSON(data interface{}) error {
+	return c.writeData(&JSONDataWriter{Indent: "    "}, data)
+}
+
+// SecureJSON writes json values to the response, prefixed with SecureJSONPrefix.
+func (c *Context) SecureJSON(data interface{}) error {
+	return c.writeData(&JSONDataWriter{Prefix: SecureJSONPrefix}, data)
+}
+
+// JSONP writes json values to the response as the argument of the JavaScript function callback,
+// or as JSON if callback is empty. A 400 HTTP error is returned if callback is not a valid function name,
+// such as "cb" or "jQuery.handle_1".
+func (c *Context) JSONP(callback string, data interface{}) error {
+	if callback == "" {
+		return c.JSON(data)
+	}
+	if !jsonpCallbackRegexp.MatchString(callback) {
+		return NewHTTPError(http.StatusBadRequest, "Invalid JSONP callback")
 	}
-	return
+	return c.writeData(&JSONPDataWriter{Callback: callback}, data)
+}
+
+// XML writes xml values to the response.
+// The XML is indented if the router is in debug mode.
+func (c *Context) XML(data interface{}) error {
+	if c.router != nil && c.router.Debug {
+		return c.writeData(&XMLDataWriter{Indent: "    "}, data)
+	}
+	return c.writeData(&XMLDataWriter{}, data)
+}
+
+// Blob writes data to the response with the given content type.
+func (c *Context) Blob(contentType string, data []byte) error {
+	return c.writeWithContentType(contentType, data)
+}
+
+// NoContent sends the response with the status code 204 and no body.
+func (c *Context) NoContent() error {
+	c.Response.WriteHeader(http.StatusNoContent)
+	return nil
 }
 
 // Text writes text values to the response.
@@ -252,6 +384,13 @@ func (c *Context) IsAjax() bool {
 	return c.Request.Header.Get("X-Requested-With") == "XMLHttpRequest"
 }
 
+// IsHTMX returns true if this request is sent by htmx, which sets the "HX-Request" header.
+//
+// Read more at: https://htmx.org/reference/#request_headers
+func (c *Context) IsHTMX() bool {
+	return c.Request.Header.Get("HX-Request") == "true"
+}
+
 func (c *Context) IsGet() bool {
 	return c.Request.Method == http.MethodGet
 }
@@ -306,29 +445,141 @@ func (c *Context) RequestIP() string {
 	return ip
 }
 
-// Render render with master
+// Render render with master, or with the layout of the route group if it has one
 func (c *Context) Render(name string, data interface{}) error {
-	return c.doRender(name, data, false)
-
+	return c.writeRendered(0, name, func(out io.Writer) error {
+		return c.renderTo(out, name, data, false, c.layout())
+	})
 }
 
 // Render render only file
 func (c *Context) RenderFile(name string, data interface{}) error {
-	return c.doRender(name, data, true)
+	return c.writeRendered(0, name, func(out io.Writer) error {
+		return c.renderTo(out, name, data, true, "")
+	})
+}
+
+// RenderWithLayout render within the given layout instead of the master, or only file if layout is empty.
+func (c *Context) RenderWithLayout(layout string, name string, data interface{}) error {
+	return c.writeRendered(0, name, func(out io.Writer) error {
+		return c.renderTo(out, name, data, layout == "", layout)
+	})
+}
+
+// RenderStatus render like Render and sends the given status code, e.g. for error and not found pages
+func (c *Context) RenderStatus(code int, name string, data interface{}) error {
+	return c.writeRendered(code, name, func(out io.Writer) error {
+		return c.renderTo(out, name, data, false, c.layout())
+	})
+}
+
+// RenderToString render like Render and returns the result instead of sending it, e.g. for emails
+func (c *Context) RenderToString(name string, data interface{}) (string, error) {
+	buf := renderBufferPool.Get().(*bytes.Buffer)
+	defer putRenderBuffer(buf)
+	if err := c.renderTo(buf, name, data, false, c.layout()); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// RenderBlock render only the block defined by the template name, such as {{define "content"}},
+// or by the layout the template is rendered within by Render.
+func (c *Context) RenderBlock(name string, block string, data interface{}) error {
+	return c.writeRendered(0, name, func(out io.Writer) error {
+		if c.router.Render == nil {
+			return fmt.Errorf("Render engine not found.")
+		}
+		render, ok := c.router.Render.(BlockRender)
+		if !ok {
+			return fmt.Errorf("Render engine does not support blocks.")
+		}
+		return render.RenderBlock(out, name, c.layout(), block, data, contextFuncs(c))
+	})
+}
+
+// RenderFragment render only the block of the template for htmx requests, which swap a part of the page,
+// and the full page otherwise. Boosted htmx requests, which swap the whole body, get the full page.
+func (c *Context) RenderFragment(name string, block string, data interface{}) error {
+	c.Response.Header().Add("Vary", "HX-Request")
+	if c.IsHTMX() && c.Request.Header.Get("HX-Boosted") != "true" {
+		return c.RenderBlock(name, block, data)
+	}
+	return c.Render(name, data)
+}
+
+// maxRenderBufferSize is the capacity above which a render buffer is not reused.
+const maxRenderBufferSize = 1 << 20
+
+var renderBufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+func putRenderBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxRenderBufferSize {
+		return
+	}
+	buf.Reset()
+	renderBufferPool.Put(buf)
+}
+
+// writeRendered renders the template name into a pooled buffer and sends it with the status code,
+// or the default status if it is 0. Nothing is sent if the rendering fails, so that the error handler
+// can send a complete error response.
+func (c *Context) writeRendered(code int, name string, render func(out io.Writer) error) error {
+	buf := renderBufferPool.Get().(*bytes.Buffer)
+	defer putRenderBuffer(buf)
+	if err := render(buf); err != nil {
+		return err
+	}
+	c.setHTMLContentType()
+	if r, ok := c.router.Render.(ContentTypeRender); ok {
+		if contentType := r.ContentType(name); contentType != "" {
+			c.Response.Header().Set("Content-Type", contentType)
+		}
+	}
+	if code != 0 {
+		c.Response.WriteHeader(code)
+	}
+	_, err := buf.WriteTo(c.Response)
+	return err
 }
 
-func (c *Context) doRender(name string, data interface{}, isRenderFile bool) error {
+func (c *Context) renderTo(out io.Writer, name string, data interface{}, isRenderFile bool, layout string) error {
 	if c.router.Render == nil {
 		return fmt.Errorf("Render engine not found.")
 	}
-	contentType := getContentType(c.Request)
-	if contentType == "" || !strings.Contains(contentType, "text/html") {
-		c.Response.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if layout != "" {
+		render, ok := c.router.Render.(LayoutRender)
+		if !ok {
+			return fmt.Errorf("Render engine does not support layouts.")
+		}
+		return render.RenderLayout(out, name, layout, data, contextFuncs(c))
+	}
+	if render, ok := c.router.Render.(FuncsRender); ok {
+		return render.RenderFuncs(out, name, data, !isRenderFile, contextFuncs(c))
 	}
 	if isRenderFile {
-		return c.router.Render.RenderFile(c.Response, name, data)
+		return c.router.Render.RenderFile(out, name, data)
 	} else {
-		return c.router.Render.Render(c.Response, name, data)
+		return c.router.Render.Render(out, name, data)
+	}
+}
+
+// layout returns the layout of the route group of the current request, or an empty string if it has none.
+func (c *Context) layout() string {
+	if c.group == nil {
+		return ""
+	}
+	return c.group.layout
+}
+
+// setHTMLContentType sets the HTML content type of the response, unless the handler has already set one,
+// e.g. with another charset.
+func (c *Context) setHTMLContentType() {
+	contentType := c.Response.Header().Get("Content-Type")
+	if !strings.Contains(contentType, "text/html") {
+		c.Response.Header().Set("Content-Type", "text/html; charset=utf-8")
 	}
 }
 
@@ -1,12 +1,14 @@
 package tigo
 
 import (
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -164,3 +166,128 @@ func testNormalHandler(tag string) Handler {
 		return nil
 	}
 }
+
+func TestContextRenderBuffered(t *testing.T) {
+	router := New()
+	router.Render = NewViewRender(ViewRenderConfig{
+		FileSystem: fstest.MapFS{
+			"master.html": {Data: []byte(`<html>{{template "content" .}}</html>`)},
+			"page.html":   {Data: []byte(`{{define "content"}}{{.}}{{end}}`)},
+			"broken.html": {Data: []byte(`{{define "content"}}before{{index . 3}}after{{end}}`)},
+		},
+		Extension: ".html",
+		Master:    "master",
+	})
+	router.GET("/broken", func(c *Context) error { return c.Render("broken", []int{}) })
+	router.GET("/missing", func(c *Context) error { return c.RenderStatus(http.StatusNotFound, "page", "not found") })
+	router.GET("/email", func(c *Context) error {
+		s, err := c.RenderToString("page", "email")
+		if err != nil {
+			return err
+		}
+		return c.Text(strings.ToUpper(s))
+	})
+	router.GET("/latin", func(c *Context) error {
+		return c.Header("Content-Type", "text/html; charset=iso-8859-1").Render("page", "latin")
+	})
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/broken", nil)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusInternalServerError, res.Code)
+	assert.NotContains(t, res.Body.String(), "before")
+
+	res = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/missing", nil)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusNotFound, res.Code)
+	assert.Equal(t, "<html>not found</html>", res.Body.String())
+	assert.Equal(t, "text/html; charset=utf-8", res.Header().Get("Content-Type"))
+
+	// the content type of the response is kept if it is HTML, whatever the content type of the request is
+	res = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/latin", nil)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, "text/html; charset=iso-8859-1", res.Header().Get("Content-Type"))
+	res = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/missing", nil)
+	req.Header.Set("Content-Type", "text/html")
+	router.ServeHTTP(res, req)
+	assert.Equal(t, "text/html; charset=utf-8", res.Header().Get("Content-Type"))
+
+	res = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/email", nil)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, "<HTML>EMAIL</HTML>", res.Body.String())
+}
+
+func TestContextResponseBuilder(t *testing.T) {
+	type user struct {
+		XMLName struct{} `json:"-" xml:"user"`
+		ID      int      `json:"id" xml:"id"`
+		Name    string   `json:"name" xml:"name"`
+	}
+	router := New()
+	router.GET("/users/<id>", func(c *Context) error { return nil }).Name("user")
+	router.POST("/users", func(c *Context) error {
+		return c.Status(http.StatusCreated).Header("Location", c.URL("user", "id", 1)).JSON(user{ID: 1, Name: "<tigo>"})
+	})
+	router.GET("/xml", func(c *Context) error {
+		return c.Status(http.StatusAccepted).XML(user{ID: 1, Name: "tigo"})
+	})
+	router.GET("/jsonp", func(c *Context) error {
+		return c.JSONP(c.Query("callback"), []int{1, 2})
+	})
+	router.GET("/secure", func(c *Context) error {
+		return c.SecureJSON([]int{1, 2})
+	})
+	router.DELETE("/users/<id>", func(c *Context) error {
+		return c.NoContent()
+	})
+	router.GET("/blob", func(c *Context) error {
+		return c.Status(http.StatusPartialContent).Blob("image/png", []byte{0x89, 'P', 'N', 'G'})
+	})
+	serve := func(method, url string) *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, url, nil)
+		router.ServeHTTP(res, req)
+		return res
+	}
+
+	res := serve("POST", "/users")
+	assert.Equal(t, http.StatusCreated, res.Code)
+	assert.Equal(t, "/users/1", res.Header().Get("Location"))
+	assert.Equal(t, "application/json; charset=utf-8", res.Header().Get("Content-Type"))
+	assert.Equal(t, `{"id":1,"name":"\u003ctigo\u003e"}`, res.Body.String())
+
+	res = serve("GET", "/xml")
+	assert.Equal(t, http.StatusAccepted, res.Code)
+	assert.Equal(t, "application/xml; charset=utf-8", res.Header().Get("Content-Type"))
+	assert.Equal(t, xml.Header+"<user><id>1</id><name>tigo</name></user>", res.Body.String())
+
+	res = serve("GET", "/jsonp?callback=jQuery.cb_1")
+	assert.Equal(t, "application/javascript; charset=utf-8", res.Header().Get("Content-Type"))
+	assert.Equal(t, "/**/ typeof jQuery.cb_1 === 'function' && jQuery.cb_1([1,2]);", res.Body.String())
+	res = serve("GET", "/jsonp?callback=alert(1)")
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+	assert.NotContains(t, res.Header().Get("Content-Type"), "javascript")
+	res = serve("GET", "/jsonp")
+	assert.Equal(t, "[1,2]", res.Body.String())
+
+	res = serve("GET", "/secure")
+	assert.Equal(t, ")]}',\n[1,2]", res.Body.String())
+
+	res = serve("DELETE", "/users/1")
+	assert.Equal(t, http.StatusNoContent, res.Code)
+	assert.Equal(t, "", res.Body.String())
+
+	res = serve("GET", "/blob")
+	assert.Equal(t, http.StatusPartialContent, res.Code)
+	assert.Equal(t, "image/png", res.Header().Get("Content-Type"))
+
+	router.Debug = true
+	res = serve("POST", "/users")
+	assert.Equal(t, "{\n    \"id\": 1,\n    \"name\": \"\\u003ctigo\\u003e\"\n}", res.Body.String())
+	res = serve("GET", "/xml")
+	assert.Equal(t, xml.Header+"<user>\n    <id>1</id>\n    <name>tigo</name>\n</user>", res.Body.String())
+}
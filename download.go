@@ -0,0 +1,131 @@
+package tigo
+
+import (
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// fileETags caches the ETags of the files sent by Context.File, whatever their directories are.
+var fileETags = newETagCache(maxETags)
+
+// File sends the file at filePath as RouteGroup.File does with the given config, supporting conditional
+// and range requests, precompressed sidecar files and cache rules.
+// The file can be specified as an absolute file path or a path relative to the current working path.
+// A 404 HTTP error is returned if the file does not exist or is a directory.
+func (c *Context) File(filePath string, config ...StaticConfig) error {
+	cfg := StaticConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	dir, err := filepath.Abs(filepath.Dir(filePath))
+	if err != nil {
+		return NewHTTPError(http.StatusNotFound)
+	}
+	server := newFileServer(os.DirFS(dir), cfg)
+	server.root, server.etags = dir, fileETags
+	return server.serve(c, filepath.Base(filePath), false)
+}
+
+// Attachment sends the content as a file download named filename, with the Content-Disposition header
+// defined by RFC 6266. The content type is derived from the extension of filename.
+// If content is an io.ReadSeeker, such as an *os.File, conditional and range requests are supported.
+func (c *Context) Attachment(filename string, content io.Reader) error {
+	return c.sendFile("attachment", filename, content)
+}
+
+// Inline sends the content as a file named filename, displayed by the browser if possible.
+// It is otherwise the same as Attachment.
+func (c *Context) Inline(filename string, content io.Reader) error {
+	return c.sendFile("inline", filename, content)
+}
+
+// Stream sends the content with the given content type.
+// If content is an io.ReadSeeker, conditional and range requests are supported, otherwise
+// the content is copied to the response as it is read, flushing it after each read if possible.
+func (c *Context) Stream(contentType string, content io.Reader) error {
+	c.Response.Header().Set("Content-Type", contentType)
+	return c.serveContent("", content)
+}
+
+func (c *Context) sendFile(dispositionType, filename string, content io.Reader) error {
+	h := c.Response.Header()
+	h.Set("Content-Disposition", contentDisposition(dispositionType, filename))
+	if h.Get("Content-Type") == "" {
+		contentType := mime.TypeByExtension(path.Ext(filename))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		h.Set("Content-Type", contentType)
+	}
+	return c.serveContent(filename, content)
+}
+
+// serveContent sends the content with http.ServeContent if it is an io.ReadSeeker,
+// using the modification time of files for conditional requests.
+func (c *Context) serveContent(name string, content io.Reader) error {
+	if seeker, ok := content.(io.ReadSeeker); ok {
+		var modTime time.Time
+		if file, ok := content.(interface{ Stat() (fs.FileInfo, error) }); ok {
+			if fstat, err := file.Stat(); err == nil {
+				modTime = fstat.ModTime()
+			}
+		}
+		http.ServeContent(c.Response, c.Request, name, modTime, seeker)
+		return nil
+	}
+	flusher, _ := c.Response.(http.Flusher)
+	buf := make([]byte, 32<<10)
+	for {
+		n, err := content.Read(buf)
+		if n > 0 {
+			if _, err := c.Response.Write(buf[:n]); err != nil {
+				return err
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// contentDisposition returns a Content-Disposition header value as defined by RFC 6266, with an ASCII filename
+// for old clients and the UTF-8 filename encoded as defined by RFC 5987 if it is not ASCII.
+func contentDisposition(dispositionType, filename string) string {
+	var fallback, encoded strings.Builder
+	for _, r := range filename {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' || r == '/' {
+			fallback.WriteByte('_')
+		} else {
+			fallback.WriteRune(r)
+		}
+	}
+	value := dispositionType + `; filename="` + fallback.String() + `"`
+	if fallback.String() == filename {
+		return value
+	}
+	const hex = "0123456789ABCDEF"
+	for i := 0; i < len(filename); i++ {
+		b := filename[i]
+		if 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || '0' <= b && b <= '9' || strings.IndexByte("!#$&+-.^_`|~", b) >= 0 {
+			encoded.WriteByte(b)
+		} else {
+			encoded.WriteByte('%')
+			encoded.WriteByte(hex[b>>4])
+			encoded.WriteByte(hex[b&15])
+		}
+	}
+	return value + "; filename*=UTF-8''" + encoded.String()
+}
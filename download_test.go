@@ -0,0 +1,147 @@
+package tigo
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextAttachment(t *testing.T) {
+	router := New()
+	router.GET("/report", func(c *Context) error {
+		return c.Attachment("report.json", strings.NewReader(`[{"id":1,"name":"tigo"}]`))
+	})
+	router.GET("/report-utf8", func(c *Context) error {
+		return c.Inline("报告 2024.pdf", io.NopCloser(strings.NewReader("%PDF")))
+	})
+	router.GET("/events", func(c *Context) error {
+		return c.Stream("text/event-stream", io.MultiReader(strings.NewReader("data: 1\n\n"), strings.NewReader("data: 2\n\n")))
+	})
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/report", nil)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, `attachment; filename="report.json"`, res.Header().Get("Content-Disposition"))
+	assert.Equal(t, "application/json", res.Header().Get("Content-Type"))
+	assert.Equal(t, `[{"id":1,"name":"tigo"}]`, res.Body.String())
+
+	res = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/report", nil)
+	req.Header.Set("Range", "bytes=1-8")
+	router.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusPartialContent, res.Code)
+	assert.Equal(t, `{"id":1,`, res.Body.String())
+
+	res = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/report-utf8", nil)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, `inline; filename="__ 2024.pdf"; filename*=UTF-8''%E6%8A%A5%E5%91%8A%202024.pdf`, res.Header().Get("Content-Disposition"))
+	assert.Equal(t, "application/pdf", res.Header().Get("Content-Type"))
+	assert.Equal(t, "%PDF", res.Body.String())
+
+	res = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/events", nil)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, "text/event-stream", res.Header().Get("Content-Type"))
+	assert.Equal(t, "data: 1\n\ndata: 2\n\n", res.Body.String())
+	assert.True(t, res.Flushed)
+}
+
+func TestContextFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "notes.txt")
+	assert.Nil(t, os.WriteFile(file, []byte("hello tigo"), 0644))
+	router := New()
+	router.GET("/notes", func(c *Context) error {
+		return c.File(file)
+	})
+	router.GET("/missing", func(c *Context) error {
+		return c.File(filepath.Join(dir, "missing.txt"))
+	})
+	router.GET("/download", func(c *Context) error {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return c.Attachment("notes.txt", f)
+	})
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/notes", nil)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "hello tigo", res.Body.String())
+	etag := res.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	res = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/notes", nil)
+	req.Header.Set("If-None-Match", etag)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusNotModified, res.Code)
+
+	// the ETag changes when the file is modified
+	assert.Nil(t, os.WriteFile(file, []byte("hello again"), 0644))
+	assert.Nil(t, os.Chtimes(file, time.Now(), time.Now().Add(time.Minute)))
+	res = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/notes", nil)
+	req.Header.Set("If-None-Match", etag)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "hello again", res.Body.String())
+	assert.NotEqual(t, etag, res.Header().Get("ETag"))
+
+	res = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/missing", nil)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusNotFound, res.Code)
+
+	res = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/download", nil)
+	req.Header.Set("If-Modified-Since", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	router.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusNotModified, res.Code)
+	assert.Equal(t, `attachment; filename="notes.txt"`, res.Header().Get("Content-Disposition"))
+}
+
+func TestContextFileConfig(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.js")
+	assert.Nil(t, os.WriteFile(file, []byte("var tigo;"), 0644))
+	assert.Nil(t, os.WriteFile(file+".gz", []byte("gzipped"), 0644))
+	config := StaticConfig{Precompressed: true, CacheRules: []CacheRule{{Pattern: "*.js", Value: "no-cache"}}}
+
+	router := New()
+	router.File("/app.js", file)
+	router.GET("/context", func(c *Context) error {
+		return c.File(file)
+	})
+	router.GET("/compressed", func(c *Context) error {
+		return c.File(file, config)
+	})
+
+	serve := func(url string, accept string) *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", url, nil)
+		req.Header.Set("Accept-Encoding", accept)
+		router.ServeHTTP(res, req)
+		return res
+	}
+	res := serve("/app.js", "")
+	assert.Equal(t, "var tigo;", res.Body.String())
+	assert.NotEmpty(t, res.Header().Get("ETag"))
+	assert.Equal(t, res.Header().Get("ETag"), serve("/context", "").Header().Get("ETag"))
+
+	res = serve("/compressed", "gzip")
+	assert.Equal(t, "gzipped", res.Body.String())
+	assert.Equal(t, "gzip", res.Header().Get("Content-Encoding"))
+	assert.Equal(t, "no-cache", res.Header().Get("Cache-Control"))
+}
@@ -4,7 +4,10 @@
 
 package tigo
 
-import "net/http"
+import (
+	"errors"
+	"net/http"
+)
 
 // HTTPError represents an HTTP error with HTTP status code and error message
 type HTTPError interface {
@@ -13,28 +16,123 @@ type HTTPError interface {
 	StatusCode() int
 }
 
-// Error contains the error information reported by calling Context.Error().
-type httpError struct {
-	Status  int    `json:"status" xml:"status"`
-	Message string `json:"message" xml:"message"`
+// StatusError is the HTTPError created by NewHTTPError and NewStatusError.
+// Its message is public and sent to clients, together with its code and details.
+// Its internal message and cause are only part of the error string, which is logged.
+// Use the With methods to set the optional fields: they return a copy of the error,
+// so that errors declared as package variables are never modified.
+type StatusError struct {
+	Status   int         `json:"status" xml:"status"`
+	Message  string      `json:"message" xml:"message"`
+	Code     string      `json:"code,omitempty" xml:"code,omitempty"` // machine-readable error code, such as "user_not_found"
+	Details  interface{} `json:"details,omitempty" xml:"-"`           // details sent to clients, such as validation errors
+	Internal string      `json:"-" xml:"-"`                           // internal message, never sent to clients
+	Headers  http.Header `json:"-" xml:"-"`                           // headers of the error response
+	Cause    error       `json:"-" xml:"-"`                           // underlying error, never sent to clients
 }
 
 // NewHTTPError creates a new HttpError instance.
 // If the error message is not given, http.StatusText() will be called
 // to generate the message based on the status code.
+// The error is a *StatusError, which can be created with NewStatusError to set its optional fields.
 func NewHTTPError(status int, message ...string) HTTPError {
+	return NewStatusError(status, message...)
+}
+
+// NewStatusError creates a new StatusError.
+// If the error message is not given, http.StatusText() will be called
+// to generate the message based on the status code.
+func NewStatusError(status int, message ...string) *StatusError {
 	if len(message) > 0 {
-		return &httpError{status, message[0]}
+		return &StatusError{Status: status, Message: message[0]}
+	}
+	return &StatusError{Status: status, Message: http.StatusText(status)}
+}
+
+// Error returns the error message, followed by the internal message and the cause if any.
+func (e *StatusError) Error() string {
+	s := e.Message
+	if e.Internal != "" {
+		s += ": " + e.Internal
 	}
-	return &httpError{status, http.StatusText(status)}
+	if e.Cause != nil {
+		s += ": " + e.Cause.Error()
+	}
+	return s
 }
 
-// Error returns the error message.
-func (e *httpError) Error() string {
+// PublicMessage returns the message sent to clients.
+func (e *StatusError) PublicMessage() string {
 	return e.Message
 }
 
 // StatusCode returns the HTTP status code.
-func (e *httpError) StatusCode() int {
+func (e *StatusError) StatusCode() int {
 	return e.Status
 }
+
+// Unwrap returns the cause of the error.
+func (e *StatusError) Unwrap() error {
+	return e.Cause
+}
+
+// WithCause returns a copy of the error wrapping the given cause.
+func (e *StatusError) WithCause(cause error) *StatusError {
+	c := *e
+	c.Cause = cause
+	return &c
+}
+
+// WithInternal returns a copy of the error with the given internal message.
+func (e *StatusError) WithInternal(message string) *StatusError {
+	c := *e
+	c.Internal = message
+	return &c
+}
+
+// WithCode returns a copy of the error with the given machine-readable code.
+func (e *StatusError) WithCode(code string) *StatusError {
+	c := *e
+	c.Code = code
+	return &c
+}
+
+// WithDetails returns a copy of the error with the given details.
+func (e *StatusError) WithDetails(details interface{}) *StatusError {
+	c := *e
+	c.Details = details
+	return &c
+}
+
+// WithHeader returns a copy of the error with the given response header.
+func (e *StatusError) WithHeader(key, value string) *StatusError {
+	c := *e
+	c.Headers = e.Headers.Clone()
+	if c.Headers == nil {
+		c.Headers = make(http.Header)
+	}
+	c.Headers.Add(key, value)
+	return &c
+}
+
+// publicMessage returns the message of err that can be sent to clients.
+// It is the public message of errors having one, or the message of other HTTPErrors.
+func publicMessage(err HTTPError) string {
+	var public interface{ PublicMessage() string }
+	if errors.As(err, &public) {
+		return public.PublicMessage()
+	}
+	return err.Error()
+}
+
+// statusCodeOf returns the status code that will be sent for err, or status if err is nil.
+func statusCodeOf(err error, status int) int {
+	if err == nil {
+		return status
+	}
+	var httpError HTTPError
+	if errors.As(err, &httpError) {
+		return httpError.StatusCode()
+	}
+	return http.StatusInternalServerError
+}
@@ -5,8 +5,13 @@
 package tigo
 
 import (
+	"bytes"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -24,3 +29,38 @@ func TestNewHttpError(t *testing.T) {
 	s, _ := json.Marshal(e)
 	assert.Equal(t, `{"status":404,"message":"abc"}`, string(s))
 }
+
+func TestStatusError(t *testing.T) {
+	errNotFound := NewStatusError(http.StatusNotFound, "User not found")
+	e := errNotFound.WithCause(sql.ErrNoRows).WithInternal("user 1").WithCode("user_not_found").
+		WithDetails(M{"id": 1}).WithHeader("Cache-Control", "no-store")
+	assert.Equal(t, "User not found: user 1: sql: no rows in result set", e.Error())
+	assert.Equal(t, "User not found", e.PublicMessage())
+	assert.True(t, errors.Is(e, sql.ErrNoRows))
+	assert.Equal(t, "User not found", errNotFound.Error())
+	assert.Nil(t, errNotFound.Headers)
+
+	s, _ := json.Marshal(e)
+	assert.Equal(t, `{"status":404,"message":"User not found","code":"user_not_found","details":{"id":1}}`, string(s))
+
+	var logs bytes.Buffer
+	router := New()
+	router.Use(LoggerWithConfig(LoggerConfig{Writer: &logs}))
+	router.GET("/users/<id>", func(c *Context) error {
+		return fmt.Errorf("loading user %v: %w", c.Param("id"), e)
+	})
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/users/1", nil)
+	req.Header.Set("Accept", "application/json")
+	router.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusNotFound, res.Code)
+	assert.Equal(t, "no-store", res.Header().Get("Cache-Control"))
+	assert.Equal(t, `{"code":"user_not_found","detail":"User not found","details":{"id":1},"instance":"/users/1","status":404,"title":"Not Found","type":"about:blank"}`, res.Body.String())
+	assert.Contains(t, logs.String(), `"status":"404"`)
+	assert.Contains(t, logs.String(), "loading user 1: User not found: user 1: sql: no rows in result set")
+
+	res = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/users/1", nil)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, "User not found\n", res.Body.String())
+}
@@ -0,0 +1,118 @@
+package tigo
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// errorStatus maps the errors matching target to a status code.
+type errorStatus struct {
+	target error
+	status int
+}
+
+// errorRoute is an error handler registered for the errors matching target, or for a status code if target is nil.
+type errorRoute struct {
+	target  error
+	status  int
+	handler ErrorHandler
+}
+
+// MapError makes the errors matching target, such as a domain error ErrNotFound, HTTP errors with the given status code.
+// An error matches target if errors.Is(err, target) is true, or, if target is a nil pointer such as
+// (*ValidationError)(nil), if the error has the type of target according to errors.As.
+// The mapped error has the status text as message and the original error as cause.
+// The mappings of a group apply to the routes of the group and of its subgroups, and take
+// precedence over the mappings of its parent groups.
+func (rg *RouteGroup) MapError(target error, status int) *RouteGroup {
+	rg.errorStatuses = append(rg.errorStatuses, errorStatus{target, status})
+	return rg
+}
+
+// HandleError registers the handler of the errors matching target, which is matched as in MapError.
+// The error handlers of a group handle the errors of the routes of the group and of its subgroups,
+// and take precedence over the error handlers of its parent groups and Router.OnError.
+func (rg *RouteGroup) HandleError(target error, handler ErrorHandler) *RouteGroup {
+	rg.errorRoutes = append(rg.errorRoutes, errorRoute{target: target, handler: handler})
+	return rg
+}
+
+// HandleStatus registers the handler of the errors with the given status code, such as 404 or 500.
+// It also handles the requests that match no route under the group prefix.
+// Errors which are not HTTPErrors have the status code 500.
+func (rg *RouteGroup) HandleStatus(status int, handler ErrorHandler) *RouteGroup {
+	rg.errorRoutes = append(rg.errorRoutes, errorRoute{status: status, handler: handler})
+	return rg
+}
+
+// HandleNotFound specifies the handlers invoked when a request under the group prefix matches no route,
+// instead of the handlers specified with Router.NotFound. The handlers of the group are invoked first,
+// then MethodNotAllowedHandler, so that requests matching a route with another method get a 405 error,
+// as with Router.NotFound, and then the given handlers.
+// The handlers of the group with the longest prefix containing the request path are used,
+// or those of its closest parent group specifying some. If several groups have this prefix,
+// the last one created specifying handlers is used.
+func (rg *RouteGroup) HandleNotFound(handlers ...Handler) *RouteGroup {
+	rg.notFound = append([]Handler{MethodNotAllowedHandler}, handlers...)
+	rg.notFoundHandlers = combineHandlers(rg.handlers, rg.notFound)
+	return rg
+}
+
+// mapError converts err with the first mapping matching it, from the group to its parents.
+func (rg *RouteGroup) mapError(err error) error {
+	for g := rg; g != nil; g = g.parent {
+		for _, m := range g.errorStatuses {
+			if errorMatches(err, m.target) {
+				return NewStatusError(m.status).WithCause(err)
+			}
+		}
+	}
+	return err
+}
+
+// errorHandler returns the first error handler matching err, from the group to its parents, or nil if none matches.
+func (rg *RouteGroup) errorHandler(err error) ErrorHandler {
+	status := statusCodeOf(err, http.StatusInternalServerError)
+	for g := rg; g != nil; g = g.parent {
+		for _, route := range g.errorRoutes {
+			if route.target == nil && route.status == status || route.target != nil && errorMatches(err, route.target) {
+				return route.handler
+			}
+		}
+	}
+	return nil
+}
+
+// errorMatches reports whether err matches target by errors.Is, or by type if target is a nil pointer.
+func errorMatches(err, target error) bool {
+	if errors.Is(err, target) {
+		return true
+	}
+	t := reflect.TypeOf(target)
+	if t != nil && t.Kind() == reflect.Ptr && reflect.ValueOf(target).IsNil() {
+		return errors.As(err, reflect.New(t).Interface())
+	}
+	return false
+}
+
+// groupOf returns the group with the longest prefix containing the path, or the root group.
+// Among the groups with the same prefix, the last one created is returned, unless only an earlier one
+// specifies not-found handlers. Prefixes with parameters are ignored.
+func (r *Router) groupOf(path string) *RouteGroup {
+	group := &r.RouteGroup
+	for _, g := range r.groups {
+		if len(g.prefix) < len(group.prefix) || strings.Contains(g.prefix, "<") {
+			continue
+		}
+		if len(g.prefix) == len(group.prefix) && g.notFoundHandlers == nil && group.notFoundHandlers != nil {
+			continue
+		}
+		prefix := strings.TrimSuffix(g.prefix, "/")
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			group = g
+		}
+	}
+	return group
+}
@@ -0,0 +1,125 @@
+package tigo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errTestNotFound = errors.New("record not found")
+
+type testValidationError struct {
+	Field string
+}
+
+func (e *testValidationError) Error() string {
+	return e.Field + " is invalid"
+}
+
+func TestRouteGroupErrorHandlers(t *testing.T) {
+	router := New()
+	router.MapError(errTestNotFound, http.StatusNotFound)
+	router.HandleStatus(http.StatusNotFound, func(c *Context, err error) {
+		c.Response.WriteHeader(http.StatusNotFound)
+		c.HTML("<h1>page not found</h1>")
+	})
+	router.GET("/pages/<id>", func(c *Context) error { return fmt.Errorf("page %v: %w", c.Param("id"), errTestNotFound) })
+
+	api := router.Group("/api")
+	api.MapError((*testValidationError)(nil), http.StatusUnprocessableEntity)
+	api.HandleStatus(http.StatusNotFound, func(c *Context, err error) {
+		c.Response.WriteHeader(http.StatusNotFound)
+		c.JSON(M{"error": "not found"})
+	})
+	api.HandleError((*testValidationError)(nil), func(c *Context, err error) {
+		var validation *testValidationError
+		errors.As(err, &validation)
+		c.Response.WriteHeader(statusCodeOf(err, 0))
+		c.JSON(M{"field": validation.Field})
+	})
+	api.GET("/users/<id>", func(c *Context) error { return errTestNotFound })
+	api.POST("/users", func(c *Context) error { return &testValidationError{"name"} })
+	api.Group("/v2").GET("/users/<id>", func(c *Context) error { return errTestNotFound })
+
+	admin := router.Group("/admin")
+	admin.HandleNotFound(func(c *Context) error { return c.Text("admin page not found") })
+	admin.Group("/v2").HandleNotFound(func(c *Context) error { return c.Text("admin v2 page not found") })
+	admin.Group("/v3")
+	router.Group("/shop/v2").HandleNotFound(func(c *Context) error { return c.Text("shop v2 page not found") })
+	router.Group("/shop").HandleNotFound(func(c *Context) error { return c.Text("shop page not found") })
+	admin.GET("/users", func(c *Context) error { return nil })
+	router.Group("/docs").HandleNotFound(func(c *Context) error { return c.Text("docs page not found") })
+	router.Group("/docs").GET("/index", func(c *Context) error { return nil })
+	router.Group("/help").HandleNotFound(func(c *Context) error { return c.Text("old help page not found") })
+	router.Group("/help").HandleNotFound(func(c *Context) error { return c.Text("help page not found") })
+
+	tests := []struct {
+		method, url string
+		status      int
+		body        string
+	}{
+		{"GET", "/pages/1", 404, "<h1>page not found</h1>"},
+		{"GET", "/none", 404, "<h1>page not found</h1>"},
+		{"GET", "/api/users/1", 404, `{"error":"not found"}`},
+		{"GET", "/api/v2/users/1", 404, `{"error":"not found"}`},
+		{"GET", "/api/none", 404, `{"error":"not found"}`},
+		{"GET", "/apinone", 404, "<h1>page not found</h1>"},
+		{"POST", "/api/users", 422, `{"field":"name"}`},
+		{"DELETE", "/api/users", 405, "Method Not Allowed\n"},
+		{"GET", "/admin/none", 200, "admin page not found"},
+		{"GET", "/admin/v2/none", 200, "admin v2 page not found"},
+		{"GET", "/admin/v2", 200, "admin v2 page not found"},
+		{"GET", "/admin/v3/none", 200, "admin page not found"},
+		{"GET", "/shop/v2/none", 200, "shop v2 page not found"},
+		{"GET", "/shop/v20", 200, "shop page not found"},
+		{"DELETE", "/admin/users", 405, "Method Not Allowed\n"},
+		{"GET", "/docs/none", 200, "docs page not found"},
+		{"GET", "/help/none", 200, "help page not found"},
+	}
+	for _, test := range tests {
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest(test.method, test.url, nil)
+		router.ServeHTTP(res, req)
+		assert.Equal(t, test.status, res.Code, test.url)
+		assert.Equal(t, test.body, res.Body.String(), test.url)
+	}
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/admin/users", nil)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, "GET, OPTIONS", res.Header().Get("Allow"))
+}
+
+func TestRouteGroupMapErrorStatus(t *testing.T) {
+	var logs bytes.Buffer
+	m := NewMetrics()
+	router := New()
+	router.Use(LoggerWithConfig(LoggerConfig{Writer: &logs}), m.Collect())
+	api := router.Group("/api")
+	api.MapError(errTestNotFound, http.StatusNotFound)
+	api.GET("/users/<id>", func(c *Context) error { return errTestNotFound })
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/users/1", nil)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusNotFound, res.Code)
+	assert.Contains(t, logs.String(), `"status":"404"`)
+
+	res = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/metrics", nil)
+	assert.Nil(t, m.Expose(NewContext(res, req)))
+	assert.Contains(t, res.Body.String(), `tigo_http_requests_total{method="GET",route="/api/users/<id>",status="404"} 1`)
+}
+
+func TestErrorMatches(t *testing.T) {
+	assert.True(t, errorMatches(fmt.Errorf("x: %w", errTestNotFound), errTestNotFound))
+	assert.False(t, errorMatches(errors.New("record not found"), errTestNotFound))
+	assert.True(t, errorMatches(fmt.Errorf("x: %w", &testValidationError{}), (*testValidationError)(nil)))
+	assert.False(t, errorMatches(errTestNotFound, (*testValidationError)(nil)))
+	assert.False(t, errorMatches(errTestNotFound, nil))
+}
@@ -0,0 +1,248 @@
+package tigo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// templateFuncs lists the template functions available to all the templates rendered by a ViewRender.
+// The functions of ViewRenderConfig.Funcs take precedence over them.
+var templateFuncs = template.FuncMap{
+	"dict":      dict,
+	"list":      list,
+	"date":      formatDate,
+	"number":    formatNumber,
+	"json":      toJSON,
+	"safeHTML":  safeHTML,
+	"pluralize": pluralize,
+}
+
+// contextFuncMakers lists the template functions that are bound to the request being rendered.
+// Each entry creates the template function for the given context, which is nil if
+// the template is rendered outside of a request.
+var contextFuncMakers = map[string]func(c *Context) interface{}{
+	"cspNonce": func(c *Context) interface{} {
+		return func() string {
+			if c == nil {
+				return ""
+			}
+			return c.CSPNonce()
+		}
+	},
+	// url creates the URL of a named route, e.g. {{url "user" "id" .ID}}
+	"url": func(c *Context) interface{} {
+		return func(name string, pairs ...interface{}) (string, error) {
+			if c == nil || c.router == nil {
+				return "", fmt.Errorf("url: route %q is only available within a request", name)
+			}
+			route := c.router.Route(name)
+			if route == nil {
+				return "", fmt.Errorf("url: route %q not found", name)
+			}
+			return route.URL(pairs...), nil
+		}
+	},
+	// query returns a URL query parameter of the request, e.g. {{query "page"}}
+	"query": func(c *Context) interface{} {
+		return func(name string) string {
+			if c == nil {
+				return ""
+			}
+			return c.Query(name)
+		}
+	},
+	// param returns a path parameter of the route, e.g. {{param "id"}}
+	"param": func(c *Context) interface{} {
+		return func(name string) string {
+			if c == nil {
+				return ""
+			}
+			return c.Param(name)
+		}
+	},
+	// currentPath returns the path of the request URL
+	"currentPath": func(c *Context) interface{} {
+		return func() string {
+			if c == nil {
+				return ""
+			}
+			return c.Request.URL.Path
+		}
+	},
+	// isActive reports whether the request path is the given path or below it, e.g. {{if isActive "/admin"}}
+	"isActive": func(c *Context) interface{} {
+		return func(path string) bool {
+			if c == nil {
+				return false
+			}
+			current := c.Request.URL.Path
+			if current == path {
+				return true
+			}
+			return path != "/" && strings.HasPrefix(current, strings.TrimSuffix(path, "/")+"/")
+		}
+	},
+}
+
+// funcsMutex guards templateFuncs and contextFuncMakers.
+var funcsMutex sync.RWMutex
+
+// RegisterFunc adds a template function available to all the templates rendered by a ViewRender.
+// It must be called before the templates using it are parsed, i.e. before ViewRender.Init.
+func RegisterFunc(name string, fn interface{}) {
+	funcsMutex.Lock()
+	defer funcsMutex.Unlock()
+	templateFuncs[name] = fn
+}
+
+// RegisterContextFunc adds a template function bound to the request being rendered.
+// The maker creates the template function for the given context, which is nil if the template
+// is rendered outside of a request. It must be called before ViewRender.Init, like RegisterFunc.
+func RegisterContextFunc(name string, maker func(c *Context) interface{}) {
+	funcsMutex.Lock()
+	defer funcsMutex.Unlock()
+	contextFuncMakers[name] = maker
+}
+
+// contextFuncs creates the request-aware template functions for the given context.
+func contextFuncs(c *Context) template.FuncMap {
+	funcsMutex.RLock()
+	defer funcsMutex.RUnlock()
+	funcs := make(template.FuncMap, len(contextFuncMakers))
+	for name, fn := range contextFuncMakers {
+		funcs[name] = fn(c)
+	}
+	return funcs
+}
+
+// dict creates a map from a list of key and value pairs, e.g. to pass several values to a partial:
+// {{include "card" (dict "title" .Title "user" .User)}}
+func dict(pairs ...interface{}) (map[string]interface{}, error) {
+	if len(pairs)%2 != 0 {
+		return nil, errors.New("dict: odd number of arguments")
+	}
+	m := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict: key %v is not a string", pairs[i])
+		}
+		m[key] = pairs[i+1]
+	}
+	return m, nil
+}
+
+// list creates a slice from its arguments.
+func list(values ...interface{}) []interface{} {
+	return values
+}
+
+// formatDate formats a time.Time, a *time.Time or Unix seconds with a time layout, e.g. {{.Created | date "2006-01-02"}}.
+// A zero or nil time is formatted as an empty string.
+func formatDate(layout string, value interface{}) (string, error) {
+	var t time.Time
+	switch v := value.(type) {
+	case time.Time:
+		t = v
+	case *time.Time:
+		if v == nil {
+			return "", nil
+		}
+		t = *v
+	case int:
+		t = time.Unix(int64(v), 0)
+	case int64:
+		t = time.Unix(v, 0)
+	default:
+		return "", fmt.Errorf("date: unsupported value %v", value)
+	}
+	if t.IsZero() {
+		return "", nil
+	}
+	return t.Format(layout), nil
+}
+
+// formatNumber formats a number with the given number of decimals and comma separated thousands,
+// e.g. {{.Total | number 2}} writes 1,234,567.89.
+func formatNumber(decimals int, value interface{}) (string, error) {
+	v := reflect.ValueOf(value)
+	var s string
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s = strconv.FormatInt(v.Int(), 10)
+		if decimals > 0 {
+			s += "." + strings.Repeat("0", decimals)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		s = strconv.FormatUint(v.Uint(), 10)
+		if decimals > 0 {
+			s += "." + strings.Repeat("0", decimals)
+		}
+	case reflect.Float32, reflect.Float64:
+		s = strconv.FormatFloat(v.Float(), 'f', decimals, 64)
+	default:
+		return "", fmt.Errorf("number: unsupported value %v", value)
+	}
+
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	integer, fraction := s, ""
+	if dot := strings.IndexByte(s, '.'); dot >= 0 {
+		integer, fraction = s[:dot], s[dot:]
+	}
+	var buf strings.Builder
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteRune(digit)
+	}
+	return sign + buf.String() + fraction, nil
+}
+
+// toJSON encodes a value as JSON that can be used in scripts, e.g. <script>var user = {{json .User}};</script>
+func toJSON(value interface{}) (template.JS, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return template.JS(data), nil
+}
+
+// safeHTML marks a trusted string as HTML, which is then written without escaping.
+func safeHTML(s string) template.HTML {
+	return template.HTML(s)
+}
+
+// pluralize returns singular if count is 1, or plural otherwise. The plural defaults to singular with an "s" suffix,
+// e.g. {{len .Items}} {{pluralize (len .Items) "item"}}
+func pluralize(count interface{}, singular string, plural ...string) (string, error) {
+	var n float64
+	v := reflect.ValueOf(count)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = v.Float()
+	default:
+		return "", fmt.Errorf("pluralize: unsupported count %v", count)
+	}
+	if n == 1 {
+		return singular, nil
+	}
+	if len(plural) > 0 {
+		return plural[0], nil
+	}
+	return singular + "s", nil
+}
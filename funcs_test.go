@@ -0,0 +1,101 @@
+package tigo
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	d, err := dict("a", 1, "b", "x")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"a": 1, "b": "x"}, d)
+	_, err = dict("a")
+	assert.NotNil(t, err)
+	_, err = dict(1, 2)
+	assert.NotNil(t, err)
+
+	assert.Equal(t, []interface{}{1, "a"}, list(1, "a"))
+
+	when := time.Date(2016, 10, 2, 15, 4, 5, 0, time.UTC)
+	s, _ := formatDate("2006-01-02", when)
+	assert.Equal(t, "2016-10-02", s)
+	s, _ = formatDate("2006-01-02", &when)
+	assert.Equal(t, "2016-10-02", s)
+	s, _ = formatDate("2006-01-02", time.Time{})
+	assert.Equal(t, "", s)
+	s, _ = formatDate("2006", when.Unix())
+	assert.Equal(t, when.Local().Format("2006"), s)
+	_, err = formatDate("2006", "2016")
+	assert.NotNil(t, err)
+
+	tests := []struct {
+		decimals int
+		value    interface{}
+		expected string
+	}{
+		{0, 0, "0"},
+		{0, 999, "999"},
+		{0, 1000, "1,000"},
+		{2, 1234567.891, "1,234,567.89"},
+		{1, -1234.56, "-1,234.6"},
+		{2, uint8(12), "12.00"},
+		{0, int64(-123456789), "-123,456,789"},
+	}
+	for _, test := range tests {
+		s, err := formatNumber(test.decimals, test.value)
+		assert.Nil(t, err)
+		assert.Equal(t, test.expected, s)
+	}
+	_, err = formatNumber(0, "1")
+	assert.NotNil(t, err)
+
+	s, _ = pluralize(1, "item")
+	assert.Equal(t, "item", s)
+	s, _ = pluralize(0, "item")
+	assert.Equal(t, "items", s)
+	s, _ = pluralize(uint(2), "child", "children")
+	assert.Equal(t, "children", s)
+}
+
+func TestContextTemplateFuncs(t *testing.T) {
+	router := New()
+	router.Render = NewViewRender(ViewRenderConfig{
+		FileSystem: fstest.MapFS{
+			"page.html": {Data: []byte(`{{url "user" "id" (param "id")}}|{{query "tab"}}|{{currentPath}}|{{isActive "/users"}}|{{isActive "/user"}}|{{isActive "/"}}|{{include "card" (dict "name" .name "tags" (list "a" "b"))}}|<script>var v = {{json .}};</script>|{{safeHTML "<b>"}}`)},
+			"card.html": {Data: []byte(`{{.name}}:{{range .tags}}{{.}}{{end}}`)},
+			"bad.html":  {Data: []byte(`{{url "missing"}}`)},
+		},
+		Extension: ".html",
+	})
+	router.GET("/users/<id>", func(c *Context) error { return c.RenderFile("page", M{"name": "<Tigo>"}) }).Name("user")
+	router.GET("/bad", func(c *Context) error { return c.RenderFile("bad", nil) })
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/users/1?tab=info", nil)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, `/users/1|info|/users/1|true|false|false|&lt;Tigo&gt;:ab|<script>var v = {"name":"\u003cTigo\u003e"};</script>|<b>`, res.Body.String())
+
+	res = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/bad", nil)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusInternalServerError, res.Code)
+
+	RegisterContextFunc("userAgent", func(c *Context) interface{} {
+		return func() string { return c.Request.UserAgent() }
+	})
+	defer delete(contextFuncMakers, "userAgent")
+	RegisterFunc("upper", strings.ToUpper)
+	defer delete(templateFuncs, "upper")
+	render := NewViewRender(ViewRenderConfig{FileSystem: fstest.MapFS{"ua.html": {Data: []byte(`{{userAgent | upper}}`)}}, Extension: ".html"})
+	var buf bytes.Buffer
+	req.Header.Set("User-Agent", "test")
+	assert.Nil(t, render.RenderFuncs(&buf, "ua", nil, false, contextFuncs(NewContext(res, req))))
+	assert.Equal(t, "TEST", buf.String())
+}
@@ -6,16 +6,23 @@ package tigo
 
 import (
 	"strings"
-	"path/filepath"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"net/http"
 )
 
 // RouteGroup represents a group of routes that share the same path prefix.
 type RouteGroup struct {
-	prefix   string
-	router   *Router
-	handlers []Handler
+	prefix           string
+	router           *Router
+	handlers         []Handler
+	layout           string
+	parent           *RouteGroup
+	errorStatuses    []errorStatus
+	errorRoutes      []errorRoute
+	notFound         []Handler
+	notFoundHandlers []Handler
 }
 
 // newRouteGroup creates a new RouteGroup with the given path prefix, router, and handlers.
@@ -78,61 +85,54 @@ func (rg *RouteGroup) Any(path string, handlers ...Handler) *Route {
 }
 
 
-// Content returns a handler that serves the content of the specified file as the response.
-// The file to be served can be specified as an absolute file path or a path relative to RootPath (which
-// defaults to the current working path).
-// If the specified file does not exist, the handler will pass the control to the next available handler.
-func (rg *RouteGroup) File(path string, filePath string) *Route {
+// File serves the content of the specified file under the given route path.
+// The file can be specified as an absolute file path or a path relative to the current working path.
+// The config controls precompressed variants and cache headers; index, listing and SPA options are ignored.
+func (rg *RouteGroup) File(path string, filePath string, config ...StaticConfig) *Route {
+	cfg := StaticConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	server := newFileServer(os.DirFS(filepath.Dir(filePath)), cfg)
+	name := filepath.Base(filePath)
 	return rg.Any(path, func(c *Context) error {
 		if c.Request.Method != "GET" && c.Request.Method != "HEAD" {
 			return NewHTTPError(http.StatusMethodNotAllowed)
 		}
-		file, err := os.Open(filePath)
-		if err != nil {
-			return NewHTTPError(http.StatusNotFound, err.Error())
-		}
-		defer file.Close()
-		fstat, err := file.Stat()
-		if err != nil {
-			return NewHTTPError(http.StatusNotFound, err.Error())
-		} else if fstat.IsDir() {
-			return NewHTTPError(http.StatusNotFound)
-		}
-		http.ServeContent(c.Response, c.Request, filePath, fstat.ModTime(), file)
-		return nil
+		return server.serve(c, name, false)
 	})
 }
 
-// Content returns a handler that serves the content of the specified file as the response.
-// The file to be served can be specified as an absolute file path or a path relative to RootPath (which
-// defaults to the current working path).
-// If the specified file does not exist, the handler will pass the control to the next available handler.
-func (rg *RouteGroup) Static(path string, dir string) *Route {
+// Static serves the files in the directory dir under the given route path, such as "/assets/*".
+// It is equivalent to StaticFS with os.DirFS(dir).
+func (rg *RouteGroup) Static(path string, dir string, config ...StaticConfig) *Route {
+	return rg.StaticFS(path, os.DirFS(dir), config...)
+}
+
+// StaticFS serves the files in fsys, such as an embed.FS, under the given route path, such as "/assets/*".
+// Request paths are cleaned and never escape the root of fsys: paths with ".." segments,
+// backslashes or encoded separators are rejected, and hidden files are not served unless
+// allowed by the config. Directories are served with their index file or, if enabled, a listing.
+func (rg *RouteGroup) StaticFS(path string, fsys fs.FS, config ...StaticConfig) *Route {
+	cfg := StaticConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	server := newFileServer(fsys, cfg)
+	prefix := strings.TrimSuffix(strings.TrimSuffix(rg.prefix+path, "*"), "/")
 	return rg.Any(path, func(c *Context) error {
 		if c.Request.Method != "GET" && c.Request.Method != "HEAD" {
 			return NewHTTPError(http.StatusMethodNotAllowed)
 		}
-		filePath := ""
-		urlPath := c.Request.URL.Path
-		prefix := strings.TrimSuffix(path, "/*")
-		if strings.HasPrefix(urlPath, prefix) {
-			filePath = filepath.Join(dir, urlPath[len(prefix):])
-		}else{
+		name, ok := staticPath(c.Request, prefix, cfg.AllowHidden)
+		if !ok {
 			return NewHTTPError(http.StatusNotFound)
 		}
-		file, err := os.Open(filePath)
-		if err != nil {
-			return NewHTTPError(http.StatusNotFound, err.Error())
-		}
-		defer file.Close()
-		fstat, err := file.Stat()
-		if err != nil {
-			return NewHTTPError(http.StatusNotFound, err.Error())
-		} else if fstat.IsDir() {
-			return NewHTTPError(http.StatusNotFound)
+		err := server.serve(c, name, true)
+		if cfg.SPA && statusCodeOf(err, http.StatusOK) == http.StatusNotFound {
+			return server.serve(c, server.config.Index, false)
 		}
-		http.ServeContent(c.Response, c.Request, filePath, fstat.ModTime(), file)
-		return nil
+		return err
 	})
 }
 
@@ -154,19 +154,33 @@ func (rg *RouteGroup) To(methods, path string, handlers ...Handler) *Route {
 // Group creates a RouteGroup with the given route path prefix and handlers.
 // The new group will combine the existing path prefix with the new one.
 // If no handler is provided, the new group will inherit the handlers registered
-// with the current group.
+// with the current group. The new group also inherits the layout and the error handlers of the current group.
 func (rg *RouteGroup) Group(prefix string, handlers ...Handler) *RouteGroup {
 	if len(handlers) == 0 {
 		handlers = make([]Handler, len(rg.handlers))
 		copy(handlers, rg.handlers)
 	}
-	return newRouteGroup(rg.prefix+prefix, rg.router, handlers)
+	group := newRouteGroup(rg.prefix+prefix, rg.router, handlers)
+	group.layout = rg.layout
+	group.parent = rg
+	rg.router.groups = append(rg.router.groups, group)
+	return group
+}
+
+// Layout sets the layout used by Context.Render in the routes of this group, instead of the master of the render.
+// Groups created afterwards from this group inherit the layout.
+func (rg *RouteGroup) Layout(layout string) *RouteGroup {
+	rg.layout = layout
+	return rg
 }
 
 // Use registers one or multiple handlers to the current route group.
 // These handlers will be shared by all routes belong to this group and its subgroups.
 func (rg *RouteGroup) Use(handlers ...Handler) {
 	rg.handlers = append(rg.handlers, handlers...)
+	if rg.notFound != nil {
+		rg.notFoundHandlers = combineHandlers(rg.handlers, rg.notFound)
+	}
 }
 
 func (rg *RouteGroup) add(method, path string, handlers []Handler) *Route {
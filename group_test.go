@@ -6,7 +6,10 @@ package tigo
 
 import (
 	"bytes"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -91,3 +94,37 @@ func TestRouteGroupUse(t *testing.T) {
 	group2.Use(newHandler("3", &buf))
 	assert.Equal(t, 3, len(group2.handlers), "len(group2.handlers) =")
 }
+
+func TestRouteGroupLayout(t *testing.T) {
+	router := New()
+	router.Render = NewViewRender(ViewRenderConfig{
+		FileSystem: fstest.MapFS{
+			"master.html":       {Data: []byte(`<site>{{template "content" .}}</site>`)},
+			"admin/master.html": {Data: []byte(`<admin>{{template "content" .}}</admin>`)},
+			"page.html":         {Data: []byte(`{{define "content"}}page{{end}}`)},
+		},
+		Extension: ".html",
+		Master:    "master",
+	})
+	page := func(c *Context) error { return c.Render("page", nil) }
+	router.GET("/page", page)
+	router.GET("/custom", func(c *Context) error { return c.RenderWithLayout("admin/master", "page", nil) })
+	admin := router.Group("/admin").Layout("admin/master")
+	admin.GET("/page", page)
+	admin.Group("/users").GET("/page", page)
+
+	tests := []struct {
+		url, body string
+	}{
+		{"/page", "<site>page</site>"},
+		{"/custom", "<admin>page</admin>"},
+		{"/admin/page", "<admin>page</admin>"},
+		{"/admin/users/page", "<admin>page</admin>"},
+	}
+	for _, test := range tests {
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", test.url, nil)
+		router.ServeHTTP(res, req)
+		assert.Equal(t, test.body, res.Body.String(), test.url)
+	}
+}
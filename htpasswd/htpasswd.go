@@ -0,0 +1,88 @@
+// Package htpasswd authenticates the users of an Apache htpasswd file with tigo.BasicAuth.
+package htpasswd
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/foolin/tigo"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// dummyHash is compared against the password of unknown users, so that they take as long to reject
+// as known users and the response time does not reveal which usernames exist.
+const dummyHash = "$2a$10$bMzIor0Nl6pz.HiKyrOVTOVWcaLK58SxZlwVRMwxRZb7JkOrawXOu"
+
+// File holds the credentials loaded from an Apache htpasswd file.
+// Passwords hashed with bcrypt ($2a$, $2b$, $2y$) and SHA1 ({SHA}) are supported.
+type File struct {
+	users map[string]string
+}
+
+// Load reads the credentials from the given htpasswd file.
+// An error is returned if the file cannot be read or contains an unsupported hash format.
+func Load(file string) (*File, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := &File{users: make(map[string]string)}
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.Index(line, ":")
+		if i <= 0 {
+			return nil, fmt.Errorf("htpasswd %v: invalid entry at line %v", file, n)
+		}
+		hash := line[i+1:]
+		if !isBcryptHash(hash) && !strings.HasPrefix(hash, "{SHA}") {
+			return nil, fmt.Errorf("htpasswd %v: unsupported hash format at line %v", file, n)
+		}
+		h.users[line[:i]] = hash
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Verify reports whether the password is valid for the given user.
+func (h *File) Verify(username, password string) bool {
+	hash, ok := h.users[username]
+	if !ok {
+		bcrypt.CompareHashAndPassword([]byte(dummyHash), []byte(password))
+		return false
+	}
+	if isBcryptHash(hash) {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	}
+	sum := sha1.Sum([]byte(password))
+	expected := "{SHA}" + base64.StdEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(expected)) == 1
+}
+
+// AuthFunc returns a tigo.BasicAuthFunc that validates credentials against the htpasswd entries.
+// The username is used as the authenticated identity.
+func (h *File) AuthFunc() tigo.BasicAuthFunc {
+	return func(c *tigo.Context, username, password string) (tigo.Identity, error) {
+		if h.Verify(username, password) {
+			return username, nil
+		}
+		return nil, errors.New("invalid username or password")
+	}
+}
+
+func isBcryptHash(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
@@ -0,0 +1,37 @@
+package htpasswd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestLoad(t *testing.T) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	dir, _ := ioutil.TempDir("", "tigo")
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, ".htpasswd")
+	content := "# users\nalice:" + string(hash) + "\nbob:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=\n"
+	ioutil.WriteFile(file, []byte(content), 0644)
+
+	h, err := Load(file)
+	if assert.Nil(t, err) {
+		assert.True(t, h.Verify("alice", "secret"))
+		assert.False(t, h.Verify("alice", "password"))
+		assert.True(t, h.Verify("bob", "password"))
+		assert.False(t, h.Verify("bob", "secret"))
+		assert.False(t, h.Verify("carol", "secret"))
+
+		identity, err := h.AuthFunc()(nil, "alice", "secret")
+		assert.Nil(t, err)
+		assert.Equal(t, "alice", identity)
+	}
+
+	ioutil.WriteFile(file, []byte("alice:$apr1$xyz$abc\n"), 0644)
+	_, err = Load(file)
+	assert.NotNil(t, err)
+}
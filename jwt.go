@@ -0,0 +1,315 @@
+package tigo
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// JWT signing algorithms supported by JWT().
+const (
+	JWT_HS256 = "HS256"
+	JWT_RS256 = "RS256"
+	JWT_ES256 = "ES256"
+)
+
+// JWTClaims represents the claims of a verified JSON Web Token.
+type JWTClaims map[string]interface{}
+
+// JWTConfig specifies how JWT() verifies JSON Web Tokens.
+type JWTConfig struct {
+	Realm      string        //realm used in the WWW-Authenticate header, defaults to DefaultRealm
+	Key        interface{}   //verification key: []byte for HS256, *rsa.PublicKey for RS256, *ecdsa.PublicKey for ES256
+	KeySet     *JWKS         //keys selected by the "kid" token header, such as those loaded by LoadJWKS
+	Algorithms []string      //allowed algorithms, defaults to HS256, RS256 and ES256
+	Issuer     string        //expected "iss" claim, not checked if empty
+	Audience   string        //expected "aud" claim, not checked if empty
+	Leeway     time.Duration //clock skew tolerated when checking "exp" and "nbf"
+	// Identity converts the verified claims into the identity stored in the context.
+	// If nil, the claims themselves are used as the identity.
+	Identity func(c *Context, claims JWTClaims) (Identity, error)
+}
+
+// JWT returns a handler that authenticates requests with a JSON Web Token sent as a bearer token.
+// The token signature is verified with the key or key set in the config, and the "exp", "nbf",
+// "iss" and "aud" claims are validated. On success, the identity is stored in the context under UserKey.
+// Otherwise a 401 HTTP error is returned together with a WWW-Authenticate header.
+func JWT(config JWTConfig) Handler {
+	realm := config.Realm
+	if realm == "" {
+		realm = DefaultRealm
+	}
+	return BearerAuth(func(c *Context, token string) (Identity, error) {
+		claims, err := ParseJWT(token, config)
+		if err != nil {
+			return nil, err
+		}
+		if config.Identity != nil {
+			return config.Identity(c, claims)
+		}
+		return claims, nil
+	}, realm)
+}
+
+// ParseJWT verifies the given token according to the config and returns its claims.
+func ParseJWT(token string, config JWTConfig) (JWTClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("token is malformed")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return nil, errors.New("token header is malformed")
+	}
+	allowed := config.Algorithms
+	if len(allowed) == 0 {
+		allowed = []string{JWT_HS256, JWT_RS256, JWT_ES256}
+	}
+	if !containsString(allowed, header.Alg) {
+		return nil, fmt.Errorf("token algorithm %q is not allowed", header.Alg)
+	}
+
+	key := config.Key
+	if config.KeySet != nil {
+		if key = config.KeySet.Key(header.Kid); key == nil {
+			return nil, fmt.Errorf("token key %q is unknown", header.Kid)
+		}
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("token signature is malformed")
+	}
+	if err := verifyJWTSignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	claims := JWTClaims{}
+	if err := decodeJWTSegment(parts[1], &claims); err != nil {
+		return nil, errors.New("token claims are malformed")
+	}
+	if err := claims.validate(config, time.Now()); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// Subject returns the "sub" claim.
+func (c JWTClaims) Subject() string {
+	s, _ := c["sub"].(string)
+	return s
+}
+
+// Issuer returns the "iss" claim.
+func (c JWTClaims) Issuer() string {
+	s, _ := c["iss"].(string)
+	return s
+}
+
+// Audience returns the "aud" claim, which may be either a single string or a list of strings.
+func (c JWTClaims) Audience() []string {
+	switch aud := c["aud"].(type) {
+	case string:
+		return []string{aud}
+	case []interface{}:
+		list := make([]string, 0, len(aud))
+		for _, v := range aud {
+			if s, ok := v.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+// ExpiresAt returns the time given by the "exp" claim, or the zero time if it is not set.
+func (c JWTClaims) ExpiresAt() time.Time {
+	return c.time("exp")
+}
+
+// NotBefore returns the time given by the "nbf" claim, or the zero time if it is not set.
+func (c JWTClaims) NotBefore() time.Time {
+	return c.time("nbf")
+}
+
+func (c JWTClaims) time(name string) time.Time {
+	if v, ok := c[name].(float64); ok {
+		return time.Unix(int64(v), 0)
+	}
+	return time.Time{}
+}
+
+func (c JWTClaims) validate(config JWTConfig, now time.Time) error {
+	if exp := c.ExpiresAt(); !exp.IsZero() && now.After(exp.Add(config.Leeway)) {
+		return errors.New("token is expired")
+	}
+	if nbf := c.NotBefore(); !nbf.IsZero() && now.Add(config.Leeway).Before(nbf) {
+		return errors.New("token is not valid yet")
+	}
+	if config.Issuer != "" && c.Issuer() != config.Issuer {
+		return errors.New("token issuer is invalid")
+	}
+	if config.Audience != "" && !containsString(c.Audience(), config.Audience) {
+		return errors.New("token audience is invalid")
+	}
+	return nil
+}
+
+func verifyJWTSignature(alg string, key interface{}, signed string, signature []byte) error {
+	sum := sha256.Sum256([]byte(signed))
+	switch alg {
+	case JWT_HS256:
+		secret, ok := key.([]byte)
+		if !ok {
+			return errors.New("token key does not match algorithm HS256")
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return errors.New("token signature is invalid")
+		}
+		return nil
+	case JWT_RS256:
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("token key does not match algorithm RS256")
+		}
+		if rsa.VerifyPKCS1v15(pub, crypto.SHA256, sum[:], signature) != nil {
+			return errors.New("token signature is invalid")
+		}
+		return nil
+	case JWT_ES256:
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return errors.New("token key does not match algorithm ES256")
+		}
+		if len(signature) != 64 {
+			return errors.New("token signature is invalid")
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(pub, sum[:], r, s) {
+			return errors.New("token signature is invalid")
+		}
+		return nil
+	}
+	return fmt.Errorf("token algorithm %q is not supported", alg)
+}
+
+func decodeJWTSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// JWKS is a set of JSON Web Keys used to verify JSON Web Tokens.
+// RSA, EC (P-256) and symmetric ("oct") keys are supported.
+type JWKS struct {
+	keys map[string]interface{}
+}
+
+// LoadJWKS reads a JSON Web Key Set from the given file.
+func LoadJWKS(file string) (*JWKS, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ParseJWKS(data)
+}
+
+// ParseJWKS parses a JSON Web Key Set document.
+func ParseJWKS(data []byte) (*JWKS, error) {
+	var doc struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			Crv string `json:"crv"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+			K   string `json:"k"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("JWKS parse error: %v", err)
+	}
+	set := &JWKS{keys: make(map[string]interface{})}
+	for _, k := range doc.Keys {
+		switch k.Kty {
+		case "RSA":
+			n, err1 := base64.RawURLEncoding.DecodeString(k.N)
+			e, err2 := base64.RawURLEncoding.DecodeString(k.E)
+			if err1 != nil || err2 != nil {
+				return nil, fmt.Errorf("JWKS key %q: invalid RSA parameters", k.Kid)
+			}
+			set.keys[k.Kid] = &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			}
+		case "EC":
+			if k.Crv != "P-256" {
+				return nil, fmt.Errorf("JWKS key %q: unsupported curve %q", k.Kid, k.Crv)
+			}
+			x, err1 := base64.RawURLEncoding.DecodeString(k.X)
+			y, err2 := base64.RawURLEncoding.DecodeString(k.Y)
+			if err1 != nil || err2 != nil {
+				return nil, fmt.Errorf("JWKS key %q: invalid EC parameters", k.Kid)
+			}
+			set.keys[k.Kid] = &ecdsa.PublicKey{
+				Curve: elliptic.P256(),
+				X:     new(big.Int).SetBytes(x),
+				Y:     new(big.Int).SetBytes(y),
+			}
+		case "oct":
+			secret, err := base64.RawURLEncoding.DecodeString(k.K)
+			if err != nil {
+				return nil, fmt.Errorf("JWKS key %q: invalid symmetric key", k.Kid)
+			}
+			set.keys[k.Kid] = secret
+		default:
+			return nil, fmt.Errorf("JWKS key %q: unsupported key type %q", k.Kid, k.Kty)
+		}
+	}
+	return set, nil
+}
+
+// Key returns the key with the given key ID.
+// If the token does not specify a key ID and the set contains only one key, that key is returned.
+func (s *JWKS) Key(kid string) interface{} {
+	if key, ok := s.keys[kid]; ok {
+		return key
+	}
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key
+		}
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
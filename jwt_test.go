@@ -0,0 +1,126 @@
+package tigo
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testSignJWT(alg string, key interface{}, kid string, claims JWTClaims) string {
+	header, _ := json.Marshal(map[string]string{"alg": alg, "typ": "JWT", "kid": kid})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	var signature []byte
+	switch alg {
+	case JWT_HS256:
+		mac := hmac.New(sha256.New, key.([]byte))
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case JWT_ES256:
+		sum := sha256.Sum256([]byte(signed))
+		r, s, _ := ecdsa.Sign(rand.Reader, key.(*ecdsa.PrivateKey), sum[:])
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestParseJWT(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Now().Unix()
+	config := JWTConfig{Key: secret, Issuer: "tigo", Audience: "api", Leeway: time.Minute}
+
+	claims, err := ParseJWT(testSignJWT(JWT_HS256, secret, "", JWTClaims{"sub": "1", "iss": "tigo", "aud": []string{"web", "api"}, "exp": now + 60}), config)
+	if assert.Nil(t, err) {
+		assert.Equal(t, "1", claims.Subject())
+		assert.Equal(t, []string{"web", "api"}, claims.Audience())
+	}
+
+	tests := []struct {
+		id     string
+		claims JWTClaims
+		key    []byte
+		err    string
+	}{
+		{"expired", JWTClaims{"iss": "tigo", "aud": "api", "exp": now - 120}, secret, "token is expired"},
+		{"expired within leeway", JWTClaims{"iss": "tigo", "aud": "api", "exp": now - 30}, secret, ""},
+		{"not before", JWTClaims{"iss": "tigo", "aud": "api", "nbf": now + 120}, secret, "token is not valid yet"},
+		{"issuer", JWTClaims{"iss": "other", "aud": "api"}, secret, "token issuer is invalid"},
+		{"audience", JWTClaims{"iss": "tigo", "aud": "web"}, secret, "token audience is invalid"},
+		{"signature", JWTClaims{"iss": "tigo", "aud": "api"}, []byte("wrong"), "token signature is invalid"},
+	}
+	for _, test := range tests {
+		_, err := ParseJWT(testSignJWT(JWT_HS256, test.key, "", test.claims), config)
+		if test.err == "" {
+			assert.Nil(t, err, test.id)
+		} else if assert.NotNil(t, err, test.id) {
+			assert.Equal(t, test.err, err.Error(), test.id)
+		}
+	}
+
+	_, err = ParseJWT("abc.def", config)
+	assert.NotNil(t, err)
+
+	config.Algorithms = []string{JWT_RS256}
+	_, err = ParseJWT(testSignJWT(JWT_HS256, secret, "", JWTClaims{"iss": "tigo", "aud": "api"}), config)
+	assert.NotNil(t, err)
+}
+
+func TestJWKS(t *testing.T) {
+	priv, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	enc := base64.RawURLEncoding
+	doc := `{"keys":[
+		{"kid":"ec1","kty":"EC","crv":"P-256","x":"` + enc.EncodeToString(priv.X.Bytes()) + `","y":"` + enc.EncodeToString(priv.Y.Bytes()) + `"},
+		{"kid":"hs1","kty":"oct","k":"` + enc.EncodeToString([]byte("secret")) + `"}
+	]}`
+	set, err := ParseJWKS([]byte(doc))
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Nil(t, set.Key("unknown"))
+	assert.Nil(t, set.Key(""))
+
+	config := JWTConfig{KeySet: set}
+	_, err = ParseJWT(testSignJWT(JWT_ES256, priv, "ec1", JWTClaims{"sub": "1"}), config)
+	assert.Nil(t, err)
+	_, err = ParseJWT(testSignJWT(JWT_HS256, []byte("secret"), "hs1", JWTClaims{"sub": "1"}), config)
+	assert.Nil(t, err)
+	_, err = ParseJWT(testSignJWT(JWT_HS256, []byte("secret"), "ec1", JWTClaims{"sub": "1"}), config)
+	assert.NotNil(t, err)
+
+	_, err = ParseJWKS([]byte(`{"keys":[{"kid":"x","kty":"OKP"}]}`))
+	assert.NotNil(t, err)
+}
+
+func TestJWT(t *testing.T) {
+	h := JWT(JWTConfig{Key: []byte("secret"), Realm: "App"})
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/users/", nil)
+	req.Header.Set("Authorization", "Bearer "+testSignJWT(JWT_HS256, []byte("secret"), "", JWTClaims{"sub": "1"}))
+	c := NewContext(res, req)
+	assert.Nil(t, h(c))
+	if claims, ok := c.User().(JWTClaims); assert.True(t, ok) {
+		assert.Equal(t, "1", claims.Subject())
+	}
+
+	req.Header.Set("Authorization", "Bearer "+testSignJWT(JWT_HS256, []byte("secret"), "", JWTClaims{"exp": time.Now().Unix() - 10}))
+	res = httptest.NewRecorder()
+	c = NewContext(res, req)
+	err := h(c)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusUnauthorized, err.(HTTPError).StatusCode())
+	}
+	assert.Equal(t, `Bearer realm="App", error="invalid_token"`, res.Header().Get("WWW-Authenticate"))
+}
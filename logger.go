@@ -9,33 +9,46 @@ import (
 	"strconv"
 )
 
+// LoggerConfig specifies how LoggerWithConfig writes access logs.
+type LoggerConfig struct {
+	Writer       io.Writer //log writer, defaults to os.Stdout
+	RoutePattern bool      //log the matched route pattern (e.g. /users/<id>) instead of the raw request URI
+}
+
 func Logger(writer io.Writer) Handler {
+	return LoggerWithConfig(LoggerConfig{Writer: writer})
+}
+
+// LoggerWithConfig returns a logger handler using the given config.
+// With RoutePattern enabled, requests not matching any route are still logged with their raw URI.
+func LoggerWithConfig(config LoggerConfig) Handler {
+	writer := config.Writer
 	if writer == nil{
 		writer = os.Stdout
 	}
 	return func(ctx *Context) error {
 		start := time.Now()
 
-		rw := &LogResponseWriter{ctx.Response, http.StatusOK, 0}
-		ctx.Response = rw
-
-		format := `{"time":"%v","method":"%s","uri":"%s","status":"%v","referer":"%s","host":"%s","user_agent":%v,"remote_addr":"%s","latency":"%s","request_length":"%v","response_length":"%v", "error":%v}` + "\n"
+		format := `{"time":"%v","method":"%s","uri":%s,"status":"%v","referer":"%s","host":"%s","user_agent":%v,"remote_addr":"%s","latency":"%s","request_length":"%v","response_length":"%v","request_id":"%s","trace_id":"%s", "error":%v}` + "\n"
 		err := ctx.Next()
-		statusCode := rw.Status
+		statusCode := ctx.statusOf(err)
 		errmsg := ""
 		if err != nil {
-			if httpError, ok := err.(HTTPError); ok {
-				statusCode = httpError.StatusCode()
-			} else {
-				statusCode = http.StatusInternalServerError
-			}
 			errmsg = err.Error()
 		}
 		latency := time.Now().Sub(start)
+		uri := ctx.Request.URL.RequestURI()
+		if route := ctx.Route(); config.RoutePattern && route != nil {
+			uri = route.Path()
+		}
+		traceID := ""
+		if span := ctx.Span(); span != nil {
+			traceID = span.SpanContext().TraceID
+		}
 		writer.Write([]byte(fmt.Sprintf(format,
 			time.Now().Format(time.RFC3339),
 			ctx.Request.Method,
-			ctx.Request.URL.RequestURI(),
+			strconv.Quote(uri),
 			statusCode,
 			ctx.Request.Referer(),
 			ctx.Request.Host,
@@ -43,7 +56,9 @@ func Logger(writer io.Writer) Handler {
 			ctx.RequestIP(),
 			latency,
 			ctx.Request.ContentLength,
-			rw.BytesWritten,
+			ctx.Size(),
+			ctx.RequestID(),
+			traceID,
 			strconv.Quote(errmsg),
 		)))
 
@@ -53,6 +68,8 @@ func Logger(writer io.Writer) Handler {
 
 
 // LogResponseWriter wraps http.ResponseWriter in order to capture HTTP status and response length information.
+//
+// Deprecated: the status and length of every response are available with Context.StatusCode and Context.Size.
 type LogResponseWriter struct {
 	http.ResponseWriter
 	Status       int
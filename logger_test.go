@@ -0,0 +1,31 @@
+package tigo
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerRoutePattern(t *testing.T) {
+	var buf bytes.Buffer
+	r := New()
+	r.Use(LoggerWithConfig(LoggerConfig{Writer: &buf, RoutePattern: true}))
+	r.GET(`/users/<id:\d+>`, func(c *Context) error { return nil })
+
+	var entry map[string]interface{}
+	req, _ := http.NewRequest("GET", "/users/1?x=1", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, `/users/<id:\d+>`, entry["uri"])
+
+	buf.Reset()
+	req, _ = http.NewRequest("GET", "/posts?x=1", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "/posts?x=1", entry["uri"])
+	assert.Equal(t, "404", entry["status"])
+}
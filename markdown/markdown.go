@@ -0,0 +1,210 @@
+// Package markdown renders Markdown pages with a YAML front matter for a tigo.Router.
+package markdown
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/foolin/tigo"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"gopkg.in/yaml.v3"
+)
+
+// Config specifies how a Render reads and renders pages.
+type Config struct {
+	FileSystem   fs.FS             //page source, such as an embed.FS, Root is a directory within it. Default: disk
+	Root         string            //page root
+	Extension    string            //page extension, default ".md"
+	Layout       string            //layout used by Render, overridden by the "layout" field of the front matter
+	LayoutRender tigo.Render       //render of the layouts, such as a tigo.ViewRender, which are given a Page
+	Markdown     goldmark.Markdown //markdown converter, default: GitHub Flavored Markdown, without raw HTML
+	DisableCache bool              //disable cache, debug mode
+}
+
+// Page is the data given to the layout of a Markdown page.
+type Page struct {
+	Content template.HTML          //the page converted to HTML
+	Meta    map[string]interface{} //the YAML front matter of the page
+	Data    interface{}            //the data given to the render
+}
+
+// Render renders Markdown pages with an optional YAML front matter, delimited by "---" lines,
+// into a layout rendered by another tigo.Render. Without layout, only the page content is rendered.
+// It implements tigo.Render, tigo.FuncsRender and tigo.LayoutRender.
+type Render struct {
+	config Config
+	pages  map[string]*cachedPage
+	mutex  sync.RWMutex
+}
+
+type cachedPage struct {
+	content template.HTML
+	meta    map[string]interface{}
+}
+
+// New creates a new Render.
+func New(config Config) *Render {
+	if config.Extension == "" {
+		config.Extension = ".md"
+	}
+	if config.Markdown == nil {
+		config.Markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+	}
+	return &Render{config: config, pages: make(map[string]*cachedPage)}
+}
+
+// Init converts all the pages under Root, so that errors are reported before the first request.
+// A missing Root is not an error.
+func (r *Render) Init() error {
+	fsys, root := r.rootFS()
+	if _, err := fs.Stat(fsys, root); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	var errs []error
+	err := fs.WalkDir(fsys, root, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(file, r.config.Extension) {
+			return err
+		}
+		name := strings.TrimSuffix(file, r.config.Extension)
+		if root != "." {
+			name = strings.TrimPrefix(name, root+"/")
+		}
+		if _, err := r.page(name); err != nil {
+			errs = append(errs, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return errors.Join(errs...)
+}
+
+// Render a page within its layout
+func (r *Render) Render(out io.Writer, name string, data interface{}) error {
+	return r.render(out, name, "", true, data, nil)
+}
+
+// Render only the page content
+func (r *Render) RenderFile(out io.Writer, name string, data interface{}) error {
+	return r.render(out, name, "", false, data, nil)
+}
+
+// RenderFuncs render a page, passing the request-bound functions to its layout.
+func (r *Render) RenderFuncs(out io.Writer, name string, data interface{}, useMaster bool, funcs template.FuncMap) error {
+	return r.render(out, name, "", useMaster, data, funcs)
+}
+
+// RenderLayout render a page within the given layout instead of its own, or only the page content if layout is empty.
+func (r *Render) RenderLayout(out io.Writer, name string, layout string, data interface{}, funcs template.FuncMap) error {
+	return r.render(out, name, layout, false, data, funcs)
+}
+
+// render renders the page within layout, or within its own layout if pageLayout is true.
+func (r *Render) render(out io.Writer, name string, layout string, pageLayout bool, data interface{}, funcs template.FuncMap) error {
+	page, err := r.page(name)
+	if err != nil {
+		return err
+	}
+	if pageLayout {
+		layout = r.config.Layout
+		if l, ok := page.meta["layout"].(string); ok {
+			layout = l
+		}
+	}
+	if layout == "" {
+		_, err := io.WriteString(out, string(page.content))
+		return err
+	}
+	if r.config.LayoutRender == nil {
+		return fmt.Errorf("markdown.Render layout render not found for layout %v", layout)
+	}
+	pageData := &Page{Content: page.content, Meta: page.meta, Data: data}
+	if render, ok := r.config.LayoutRender.(tigo.FuncsRender); ok && funcs != nil {
+		return render.RenderFuncs(out, layout, pageData, false, funcs)
+	}
+	return r.config.LayoutRender.RenderFile(out, layout, pageData)
+}
+
+// page returns the converted page, from the cache if available.
+func (r *Render) page(name string) (*cachedPage, error) {
+	r.mutex.RLock()
+	page, ok := r.pages[name]
+	r.mutex.RUnlock()
+	if ok && !r.config.DisableCache {
+		return page, nil
+	}
+
+	fsys, root := r.rootFS()
+	file := path.Join(root, name+r.config.Extension)
+	source, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return nil, fmt.Errorf("markdown.Render read name:%v, path:%v, error: %v", name, file, err)
+	}
+	meta, body, err := parseFrontMatter(source)
+	if err != nil {
+		return nil, fmt.Errorf("markdown.Render front matter name:%v, path:%v, error: %v", name, file, err)
+	}
+	buf := new(bytes.Buffer)
+	if err := r.config.Markdown.Convert(body, buf); err != nil {
+		return nil, fmt.Errorf("markdown.Render convert name:%v, path:%v, error: %v", name, file, err)
+	}
+	page = &cachedPage{content: template.HTML(buf.String()), meta: meta}
+	r.mutex.Lock()
+	r.pages[name] = page
+	r.mutex.Unlock()
+	return page, nil
+}
+
+// rootFS returns the file system and the directory within it containing the pages.
+func (r *Render) rootFS() (fs.FS, string) {
+	if r.config.FileSystem != nil {
+		root := path.Clean("/" + r.config.Root)
+		if root == "/" {
+			return r.config.FileSystem, "."
+		}
+		return r.config.FileSystem, strings.TrimPrefix(root, "/")
+	}
+	root := r.config.Root
+	if root == "" {
+		root = "."
+	}
+	return os.DirFS(root), "."
+}
+
+// parseFrontMatter splits the YAML front matter, delimited by "---" lines, from the Markdown body.
+func parseFrontMatter(source []byte) (map[string]interface{}, []byte, error) {
+	meta := make(map[string]interface{})
+	text := bytes.TrimPrefix(source, []byte("\ufeff"))
+	if !bytes.HasPrefix(text, []byte("---\n")) && !bytes.HasPrefix(text, []byte("---\r\n")) {
+		return meta, source, nil
+	}
+	text = text[bytes.IndexByte(text, '\n')+1:]
+	for offset := 0; offset < len(text); {
+		end := bytes.IndexByte(text[offset:], '\n')
+		line := text[offset:]
+		if end >= 0 {
+			line = text[offset : offset+end+1]
+		}
+		if string(bytes.TrimRight(line, "\r\n")) == "---" {
+			if err := yaml.Unmarshal(text[:offset], &meta); err != nil {
+				return nil, nil, err
+			}
+			return meta, text[offset+len(line):], nil
+		}
+		offset += len(line)
+	}
+	return nil, nil, errors.New("front matter is not closed")
+}
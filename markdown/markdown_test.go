@@ -0,0 +1,53 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+	"testing/fstest"
+
+	"github.com/foolin/tigo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	fsys := fstest.MapFS{
+		"layouts/page.html": {Data: []byte(`<title>{{.Meta.title}}</title><main>{{.Content}}</main>{{.Data}}`)},
+		"layouts/docs.html": {Data: []byte(`<docs>{{.Content}}</docs>`)},
+		"pages/about.md":    {Data: []byte("---\ntitle: About <us>\ntags: [a, b]\n---\n# About\n\nHello <b>world</b>\n")},
+		"pages/guide.md":    {Data: []byte("---\r\nlayout: layouts/docs\r\n---\r\n*guide*\n")},
+		"pages/plain.md":    {Data: []byte("plain\n")},
+	}
+	render := New(Config{
+		FileSystem:   fsys,
+		Root:         "pages",
+		Layout:       "layouts/page",
+		LayoutRender: tigo.NewViewRender(tigo.ViewRenderConfig{FileSystem: fsys, Extension: ".html"}),
+	})
+	assert.Nil(t, render.Init())
+
+	var buf bytes.Buffer
+	assert.Nil(t, render.Render(&buf, "about", "data"))
+	assert.Equal(t, "<title>About &lt;us&gt;</title><main><h1>About</h1>\n<p>Hello <!-- raw HTML omitted -->world<!-- raw HTML omitted --></p>\n</main>data", buf.String())
+
+	buf.Reset()
+	assert.Nil(t, render.Render(&buf, "guide", nil))
+	assert.Equal(t, "<docs><p><em>guide</em></p>\n</docs>", buf.String())
+
+	buf.Reset()
+	assert.Nil(t, render.RenderFile(&buf, "plain", nil))
+	assert.Equal(t, "<p>plain</p>\n", buf.String())
+
+	buf.Reset()
+	assert.Nil(t, render.RenderLayout(&buf, "guide", "layouts/page", nil, nil))
+	assert.Equal(t, "<title></title><main><p><em>guide</em></p>\n</main>", buf.String())
+
+	assert.NotNil(t, render.Render(&buf, "missing", nil))
+
+	fsys["pages/broken.md"] = &fstest.MapFile{Data: []byte("---\ntitle: [\n---\n")}
+	assert.NotNil(t, New(Config{FileSystem: fsys, Root: "pages"}).Init())
+
+	meta, body, err := parseFrontMatter([]byte("---\ntitle: x\n"))
+	assert.Nil(t, meta)
+	assert.Nil(t, body)
+	assert.NotNil(t, err)
+}
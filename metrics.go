@@ -0,0 +1,225 @@
+package tigo
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultLatencyBuckets are the upper bounds in seconds of the request latency histogram.
+var DefaultLatencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// DefaultSizeBuckets are the upper bounds in bytes of the response size histogram.
+var DefaultSizeBuckets = []float64{100, 1000, 10000, 100000, 1000000, 10000000}
+
+// MetricsConfig specifies the metrics recorded by a Metrics collector.
+type MetricsConfig struct {
+	Namespace      string    //metric name prefix, defaults to "tigo"
+	LatencyBuckets []float64 //latency histogram buckets in any order, defaults to DefaultLatencyBuckets
+	SizeBuckets    []float64 //response size histogram buckets in any order, defaults to DefaultSizeBuckets
+}
+
+// Metrics collects HTTP request metrics and exposes them in the Prometheus text exposition format.
+// Requests are labeled by method, route pattern and status, so that the number of series
+// stays bounded regardless of the request URIs. Methods not listed in Methods are labeled "OTHER".
+type Metrics struct {
+	config   MetricsConfig
+	inFlight int64
+	mutex    sync.Mutex
+	series   map[metricKey]*metricSeries
+}
+
+type metricKey struct {
+	method, route, status string
+}
+
+type metricSeries struct {
+	latency *histogram
+	size    *histogram
+}
+
+type histogram struct {
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+// NewMetrics creates a new Metrics collector.
+// It panics if a histogram bucket is NaN or is given twice. The +Inf bucket is always added.
+func NewMetrics(config ...MetricsConfig) *Metrics {
+	m := &Metrics{series: make(map[metricKey]*metricSeries)}
+	if len(config) > 0 {
+		m.config = config[0]
+	}
+	if m.config.Namespace == "" {
+		m.config.Namespace = "tigo"
+	}
+	if len(m.config.LatencyBuckets) == 0 {
+		m.config.LatencyBuckets = DefaultLatencyBuckets
+	}
+	if len(m.config.SizeBuckets) == 0 {
+		m.config.SizeBuckets = DefaultSizeBuckets
+	}
+	m.config.LatencyBuckets = sortBuckets(m.config.LatencyBuckets)
+	m.config.SizeBuckets = sortBuckets(m.config.SizeBuckets)
+	return m
+}
+
+// Collect returns a handler that records the metrics of every request it handles.
+// Requests that do not match any route are labeled with the route "NotFound".
+func (m *Metrics) Collect() Handler {
+	return func(c *Context) error {
+		start := time.Now()
+		atomic.AddInt64(&m.inFlight, 1)
+		defer atomic.AddInt64(&m.inFlight, -1)
+
+		err := c.Next()
+
+		route := "NotFound"
+		if r := c.Route(); r != nil {
+			route = r.Path()
+		}
+		m.observe(metricKey{
+			method: methodLabel(c.Request.Method),
+			route:  route,
+			status: strconv.Itoa(c.statusOf(err)),
+		}, time.Since(start).Seconds(), float64(c.Size()))
+		return err
+	}
+}
+
+// Expose is a handler that writes the collected metrics in the Prometheus text exposition format.
+// It is usually mounted on a dedicated route, e.g. router.GET("/metrics", metrics.Expose).
+func (m *Metrics) Expose(c *Context) error {
+	c.Response.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, err := c.Response.Write(m.Bytes())
+	return err
+}
+
+// Bytes returns the collected metrics in the Prometheus text exposition format.
+func (m *Metrics) Bytes() []byte {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	keys := make([]metricKey, 0, len(m.series))
+	for key := range m.series {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].route != keys[j].route {
+			return keys[i].route < keys[j].route
+		}
+		if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+		return keys[i].status < keys[j].status
+	})
+
+	ns := m.config.Namespace
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "# HELP %s_http_requests_total Total number of HTTP requests.\n", ns)
+	fmt.Fprintf(buf, "# TYPE %s_http_requests_total counter\n", ns)
+	for _, key := range keys {
+		fmt.Fprintf(buf, "%s_http_requests_total{%s} %d\n", ns, key.labels(), m.series[key].latency.count)
+	}
+	fmt.Fprintf(buf, "# HELP %s_http_request_duration_seconds HTTP request latency in seconds.\n", ns)
+	fmt.Fprintf(buf, "# TYPE %s_http_request_duration_seconds histogram\n", ns)
+	for _, key := range keys {
+		m.series[key].latency.write(buf, ns+"_http_request_duration_seconds", key.labels())
+	}
+	fmt.Fprintf(buf, "# HELP %s_http_response_size_bytes HTTP response size in bytes.\n", ns)
+	fmt.Fprintf(buf, "# TYPE %s_http_response_size_bytes histogram\n", ns)
+	for _, key := range keys {
+		m.series[key].size.write(buf, ns+"_http_response_size_bytes", key.labels())
+	}
+	fmt.Fprintf(buf, "# HELP %s_http_requests_in_flight Number of HTTP requests being served.\n", ns)
+	fmt.Fprintf(buf, "# TYPE %s_http_requests_in_flight gauge\n", ns)
+	fmt.Fprintf(buf, "%s_http_requests_in_flight %d\n", ns, atomic.LoadInt64(&m.inFlight))
+	return buf.Bytes()
+}
+
+func (m *Metrics) observe(key metricKey, latency, size float64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	s := m.series[key]
+	if s == nil {
+		s = &metricSeries{
+			latency: newHistogram(m.config.LatencyBuckets),
+			size:    newHistogram(m.config.SizeBuckets),
+		}
+		m.series[key] = s
+	}
+	s.latency.observe(latency)
+	s.size.observe(size)
+}
+
+func (k metricKey) labels() string {
+	return `method="` + escapeLabelValue(k.method) + `",route="` + escapeLabelValue(k.route) + `",status="` + k.status + `"`
+}
+
+// methodLabel returns the method label of a request, bounding the number of series
+// when clients send arbitrary methods.
+func methodLabel(method string) string {
+	for _, m := range Methods {
+		if m == method {
+			return method
+		}
+	}
+	return "OTHER"
+}
+
+// sortBuckets returns a sorted copy of the histogram buckets, without +Inf which is always written.
+func sortBuckets(buckets []float64) []float64 {
+	sorted := make([]float64, 0, len(buckets))
+	for _, bound := range buckets {
+		if math.IsNaN(bound) {
+			panic("metrics: histogram bucket is NaN")
+		}
+		if !math.IsInf(bound, 1) {
+			sorted = append(sorted, bound)
+		}
+	}
+	sort.Float64s(sorted)
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i] == sorted[i-1] {
+			panic(fmt.Sprintf("metrics: histogram bucket %v is duplicated", sorted[i]))
+		}
+	}
+	return sorted
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+func (h *histogram) observe(v float64) {
+	for i, bound := range h.buckets {
+		if v <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+func (h *histogram) write(buf *bytes.Buffer, name, labels string) {
+	for i, bound := range h.buckets {
+		fmt.Fprintf(buf, "%s_bucket{%s,le=\"%s\"} %d\n", name, labels, strconv.FormatFloat(bound, 'g', -1, 64), h.counts[i])
+	}
+	fmt.Fprintf(buf, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, h.count)
+	fmt.Fprintf(buf, "%s_sum{%s} %s\n", name, labels, strconv.FormatFloat(h.sum, 'g', -1, 64))
+	fmt.Fprintf(buf, "%s_count{%s} %d\n", name, labels, h.count)
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(v string) string {
+	return labelValueReplacer.Replace(v)
+}
@@ -0,0 +1,62 @@
+package tigo
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics(MetricsConfig{LatencyBuckets: []float64{1}, SizeBuckets: []float64{10, 2, math.Inf(1)}})
+	r := New()
+	r.Use(m.Collect())
+	r.GET("/users/<id>", func(c *Context) error {
+		return c.Text("hello")
+	})
+	r.GET("/error", func(c *Context) error {
+		return NewHTTPError(http.StatusBadRequest)
+	})
+
+	for _, path := range []string{"/users/1", "/users/2", "/error", "/posts"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	req, _ := http.NewRequest("X-RANDOM-1", "/posts", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	res := httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/metrics", nil)
+	assert.Nil(t, m.Expose(NewContext(res, req)))
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", res.Header().Get("Content-Type"))
+	body := res.Body.String()
+	for _, line := range []string{
+		`tigo_http_requests_total{method="GET",route="/users/<id>",status="200"} 2`,
+		`tigo_http_requests_total{method="GET",route="/error",status="400"} 1`,
+		`tigo_http_requests_total{method="GET",route="NotFound",status="404"} 1`,
+		`tigo_http_request_duration_seconds_bucket{method="GET",route="/users/<id>",status="200",le="1"} 2`,
+		`tigo_http_request_duration_seconds_count{method="GET",route="/users/<id>",status="200"} 2`,
+		`tigo_http_response_size_bytes_bucket{method="GET",route="/users/<id>",status="200",le="2"} 0`,
+		`tigo_http_response_size_bytes_bucket{method="GET",route="/users/<id>",status="200",le="10"} 2`,
+		`tigo_http_response_size_bytes_sum{method="GET",route="/users/<id>",status="200"} 10`,
+		`tigo_http_requests_total{method="OTHER",route="NotFound",status="404"} 1`,
+		`tigo_http_requests_in_flight 0`,
+	} {
+		assert.True(t, strings.Contains(body, line+"\n"), line)
+	}
+	assert.False(t, strings.Contains(body, "/users/1"))
+	assert.False(t, strings.Contains(body, "X-RANDOM-1"))
+	assert.True(t, strings.Index(body, `status="200",le="2"`) < strings.Index(body, `status="200",le="10"`))
+	assert.Equal(t, 1, strings.Count(body, `tigo_http_response_size_bytes_bucket{method="GET",route="/users/<id>",status="200",le="+Inf"}`))
+
+	assert.Panics(t, func() { NewMetrics(MetricsConfig{LatencyBuckets: []float64{1, 1}}) })
+	assert.Panics(t, func() { NewMetrics(MetricsConfig{SizeBuckets: []float64{math.NaN()}}) })
+}
+
+func TestEscapeLabelValue(t *testing.T) {
+	assert.Equal(t, `/users/<id:\\d+>`, escapeLabelValue(`/users/<id:\d+>`))
+	assert.Equal(t, `a\"b\nc`, escapeLabelValue("a\"b\nc"))
+}
@@ -0,0 +1,142 @@
+package tigo
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"path"
+	"strings"
+)
+
+// MultiRender is a Render that selects the engine of a template by the extension of its name.
+// A template named "mail/welcome.txt" is rendered as "mail/welcome" by the engine registered
+// for ".txt", and templates without a registered extension are rendered by the default engine.
+// Layouts are passed unchanged to the engine rendering the template.
+type MultiRender struct {
+	defaultRender Render
+	renders       map[string]Render
+}
+
+// NewMultiRender creates a MultiRender using defaultRender for the templates without a registered extension.
+func NewMultiRender(defaultRender Render) *MultiRender {
+	return &MultiRender{
+		defaultRender: defaultRender,
+		renders:       make(map[string]Render),
+	}
+}
+
+// Register registers the engine rendering the templates with the given extension, such as ".md".
+func (m *MultiRender) Register(ext string, render Render) *MultiRender {
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	m.renders[ext] = render
+	return m
+}
+
+// Init initializes all the engines and returns their errors together.
+func (m *MultiRender) Init() error {
+	var errs []error
+	for _, render := range m.all() {
+		if err := render.Init(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Render a template to the screen
+func (m *MultiRender) Render(out io.Writer, name string, data interface{}) error {
+	render, name, err := m.engine(name)
+	if err != nil {
+		return err
+	}
+	return render.Render(out, name, data)
+}
+
+// Render only file.
+func (m *MultiRender) RenderFile(out io.Writer, name string, data interface{}) error {
+	render, name, err := m.engine(name)
+	if err != nil {
+		return err
+	}
+	return render.RenderFile(out, name, data)
+}
+
+// RenderFuncs render a template with request-bound functions, if its engine supports them.
+func (m *MultiRender) RenderFuncs(out io.Writer, name string, data interface{}, useMaster bool, funcs template.FuncMap) error {
+	render, name, err := m.engine(name)
+	if err != nil {
+		return err
+	}
+	if r, ok := render.(FuncsRender); ok {
+		return r.RenderFuncs(out, name, data, useMaster, funcs)
+	}
+	if useMaster {
+		return render.Render(out, name, data)
+	}
+	return render.RenderFile(out, name, data)
+}
+
+// RenderLayout render a template within the given layout, if its engine supports layouts.
+func (m *MultiRender) RenderLayout(out io.Writer, name string, layout string, data interface{}, funcs template.FuncMap) error {
+	render, name, err := m.engine(name)
+	if err != nil {
+		return err
+	}
+	r, ok := render.(LayoutRender)
+	if !ok {
+		return fmt.Errorf("Render engine of %v does not support layouts.", name)
+	}
+	return r.RenderLayout(out, name, layout, data, funcs)
+}
+
+// RenderBlock render a single block of a template, if its engine supports blocks.
+func (m *MultiRender) RenderBlock(out io.Writer, name string, layout string, block string, data interface{}, funcs template.FuncMap) error {
+	render, name, err := m.engine(name)
+	if err != nil {
+		return err
+	}
+	r, ok := render.(BlockRender)
+	if !ok {
+		return fmt.Errorf("Render engine of %v does not support blocks.", name)
+	}
+	return r.RenderBlock(out, name, layout, block, data, funcs)
+}
+
+// ContentType returns the content type of the template given by its engine.
+func (m *MultiRender) ContentType(name string) string {
+	render, name, err := m.engine(name)
+	if err != nil {
+		return ""
+	}
+	if r, ok := render.(ContentTypeRender); ok {
+		return r.ContentType(name)
+	}
+	return ""
+}
+
+// engine returns the engine of the named template and the name of the template for that engine.
+func (m *MultiRender) engine(name string) (Render, string, error) {
+	ext := path.Ext(name)
+	if render, ok := m.renders[ext]; ok && ext != "" {
+		return render, strings.TrimSuffix(name, ext), nil
+	}
+	if m.defaultRender == nil {
+		return nil, name, fmt.Errorf("Render engine not found for %v.", name)
+	}
+	return m.defaultRender, name, nil
+}
+
+// all returns the default engine and the registered engines.
+func (m *MultiRender) all() []Render {
+	renders := make([]Render, 0, len(m.renders)+1)
+	if m.defaultRender != nil {
+		renders = append(renders, m.defaultRender)
+	}
+	for _, render := range m.renders {
+		renders = append(renders, render)
+	}
+	return renders
+}
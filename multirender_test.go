@@ -0,0 +1,59 @@
+package tigo
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiRender(t *testing.T) {
+	fsys := fstest.MapFS{
+		"master.html":        {Data: []byte(`<html>{{template "content" .}}</html>`)},
+		"index.html":         {Data: []byte(`{{define "content"}}{{.}}{{end}}`)},
+		"mail/welcome.txt":   {Data: []byte(`Hello {{.}} & welcome, {{include "mail/signature"}}`)},
+		"mail/signature.txt": {Data: []byte(`<tigo>`)},
+	}
+	render := NewMultiRender(NewViewRender(ViewRenderConfig{FileSystem: fsys, Extension: ".html", Master: "master"})).
+		Register(".txt", NewViewRender(ViewRenderConfig{FileSystem: fsys, Extension: ".txt", PlainText: true})).
+		Register("custom", RenderFunc(func(out io.Writer, name string, data interface{}) error {
+			_, err := fmt.Fprintf(out, "custom %v: %v", name, data)
+			return err
+		}))
+	assert.Nil(t, render.Init())
+
+	router := New()
+	router.Render = render
+	router.GET("/", func(c *Context) error { return c.Render("index", "<home>") })
+	router.GET("/mail", func(c *Context) error { return c.RenderFile("mail/welcome.txt", "<bob>") })
+	router.GET("/custom", func(c *Context) error { return c.Render("page.custom", 1) })
+	router.GET("/layout", func(c *Context) error { return c.RenderWithLayout("master", "page.custom", 1) })
+
+	tests := []struct {
+		url, contentType, body string
+	}{
+		{"/", "text/html; charset=utf-8", "<html>&lt;home&gt;</html>"},
+		{"/mail", "text/plain; charset=utf-8", "Hello <bob> & welcome, <tigo>"},
+		{"/custom", "text/html; charset=utf-8", "custom page: 1"},
+	}
+	for _, test := range tests {
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", test.url, nil)
+		router.ServeHTTP(res, req)
+		assert.Equal(t, test.body, res.Body.String(), test.url)
+		assert.Equal(t, test.contentType, res.Header().Get("Content-Type"), test.url)
+	}
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/layout", nil)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusInternalServerError, res.Code)
+
+	var buf bytes.Buffer
+	assert.NotNil(t, NewMultiRender(nil).Render(&buf, "index", nil))
+}
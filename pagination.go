@@ -0,0 +1,177 @@
+package tigo
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PaginationConfig specifies the parameters accepted by Context.Paginate.
+type PaginationConfig struct {
+	DefaultPerPage int           //page size used when per_page is not given, default 20
+	MaxPerPage     int           //maximum page size, larger per_page values are reduced to it, default 100
+	MaxPage        int           //maximum page number, larger pages are rejected; default: the last page whose offset is an int
+	SortFields     []string      //fields allowed in the sort parameter, e.g. "created_at"; empty disallows sorting
+	DefaultSort    string        //sort used when the sort parameter is not given, e.g. "-created_at"
+	Filters        []string      //filters allowed as filter[name] parameters; empty disallows filters
+	Route          string        //name of the route of the Link header URLs, default: the route of the request
+	RouteParams    []interface{} //path parameters of the Link header URLs as name and value pairs, e.g. "id", 7; default: those of the request
+}
+
+// SortField is a field of the sort parameter, such as "-created_at" for a descending sort.
+type SortField struct {
+	Name string //field name
+	Desc bool   //descending order
+}
+
+// Pagination holds the pagination, sorting and filtering parameters of a list request, read from the query:
+// page, per_page, cursor, sort such as "-created_at,name", and filters such as "filter[status]=open".
+type Pagination struct {
+	Page    int               //page number, from 1
+	PerPage int               //page size
+	Cursor  string            //position of cursor-based pagination, from the cursor parameter
+	Sort    []SortField       //sort fields, in order
+	Filters map[string]string //filter values by name
+	context *Context
+	config  PaginationConfig
+}
+
+// Paginate reads the pagination, sorting and filtering parameters of the request according to the config.
+// A 400 HTTP error is returned if a parameter is invalid, or if a sort field or a filter is not allowed.
+func (c *Context) Paginate(config PaginationConfig) (*Pagination, error) {
+	if config.DefaultPerPage <= 0 {
+		config.DefaultPerPage = 20
+	}
+	if config.MaxPerPage <= 0 {
+		config.MaxPerPage = 100
+	}
+	p := &Pagination{context: c, config: config, Cursor: c.Query("cursor"), Filters: make(map[string]string)}
+	var err error
+	if p.Page, err = c.QueryInt("page", 1); err != nil {
+		return nil, err
+	}
+	if p.Page < 1 {
+		return nil, NewHTTPError(http.StatusBadRequest, "Query parameter page must be a positive integer")
+	}
+	if p.PerPage, err = c.QueryInt("per_page", config.DefaultPerPage); err != nil {
+		return nil, err
+	}
+	if p.PerPage < 1 {
+		return nil, NewHTTPError(http.StatusBadRequest, "Query parameter per_page must be a positive integer")
+	}
+	if p.PerPage > config.MaxPerPage {
+		p.PerPage = config.MaxPerPage
+	}
+	maxPage := math.MaxInt/p.PerPage + 1
+	if config.MaxPage > 0 && config.MaxPage < maxPage {
+		maxPage = config.MaxPage
+	}
+	if p.Page > maxPage {
+		return nil, NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Query parameter page must not be greater than %v", maxPage))
+	}
+
+	sort := c.Query("sort", config.DefaultSort)
+	for _, field := range strings.Split(sort, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		f := SortField{Name: strings.TrimPrefix(field, "-"), Desc: strings.HasPrefix(field, "-")}
+		if !containsString(config.SortFields, f.Name) {
+			return nil, NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Sorting by %v is not allowed", f.Name))
+		}
+		p.Sort = append(p.Sort, f)
+	}
+
+	for name, value := range c.QueryMap("filter") {
+		if !containsString(config.Filters, name) {
+			return nil, NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Filtering by %v is not allowed", name))
+		}
+		p.Filters[name] = value
+	}
+	return p, nil
+}
+
+// Offset returns the number of items before the page.
+func (p *Pagination) Offset() int {
+	return (p.Page - 1) * p.PerPage
+}
+
+// Limit returns the page size.
+func (p *Pagination) Limit() int {
+	return p.PerPage
+}
+
+// SetHeaders writes the X-Total-Count header with the total number of items, and the Link header
+// defined by RFC 8288 with the URLs of the first, previous, next and last pages.
+func (p *Pagination) SetHeaders(total int) {
+	last := (total + p.PerPage - 1) / p.PerPage
+	if last < 1 {
+		last = 1
+	}
+	links := []string{p.link("first", "page", strconv.Itoa(1))}
+	if p.Page > 1 {
+		links = append(links, p.link("prev", "page", strconv.Itoa(min(p.Page-1, last))))
+	}
+	if p.Page < last {
+		links = append(links, p.link("next", "page", strconv.Itoa(p.Page+1)))
+	}
+	links = append(links, p.link("last", "page", strconv.Itoa(last)))
+	h := p.context.Response.Header()
+	h.Set("X-Total-Count", strconv.Itoa(total))
+	h.Set("Link", strings.Join(links, ", "))
+}
+
+// SetCursorHeaders writes the Link header defined by RFC 8288 with the URLs of the next and previous pages
+// of cursor-based pagination, omitting the links whose cursor is empty.
+func (p *Pagination) SetCursorHeaders(next, prev string) {
+	var links []string
+	if prev != "" {
+		links = append(links, p.link("prev", "cursor", prev))
+	}
+	if next != "" {
+		links = append(links, p.link("next", "cursor", next))
+	}
+	if len(links) > 0 {
+		p.context.Response.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
+
+// link returns a link to the URL of the request, or of the configured route, with the given query parameter.
+func (p *Pagination) link(rel, name, value string) string {
+	return fmt.Sprintf(`<%s>; rel="%s"`, p.url(name, value), rel)
+}
+
+// url returns the URL of the route of the pagination with the configured path parameters, or those of the request,
+// and with the query of the request where the page or cursor parameter is replaced, and per_page is bounded.
+func (p *Pagination) url(name, value string) string {
+	c := p.context
+	route := c.route
+	if p.config.Route != "" && c.router != nil {
+		route = c.router.namedRoutes[p.config.Route]
+	}
+	path := c.Request.URL.Path
+	if route != nil {
+		// Route.URL replaces a parameter with its first value, so the configured ones take precedence
+		pairs := make([]interface{}, 0, len(p.config.RouteParams)+len(c.pnames)*2)
+		pairs = append(pairs, p.config.RouteParams...)
+		for i, n := range c.pnames {
+			pairs = append(pairs, n, c.pvalues[i])
+		}
+		path = route.URL(pairs...)
+	}
+	query := url.Values{}
+	for k, v := range c.QueryValues() {
+		query[k] = v
+	}
+	if query.Has("per_page") {
+		query.Set("per_page", strconv.Itoa(p.PerPage))
+	}
+	query.Del("page")
+	query.Del("cursor")
+	query.Set(name, value)
+	return path + "?" + query.Encode()
+}
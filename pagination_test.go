@@ -0,0 +1,113 @@
+package tigo
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextPaginate(t *testing.T) {
+	config := PaginationConfig{
+		MaxPerPage:  50,
+		SortFields:  []string{"created_at", "name"},
+		DefaultSort: "-created_at",
+		Filters:     []string{"status"},
+	}
+	var pagination *Pagination
+	router := New()
+	router.GET("/projects/<id>/issues", func(c *Context) error {
+		p, err := c.Paginate(config)
+		if err != nil {
+			return err
+		}
+		pagination = p
+		p.SetHeaders(95)
+		return c.JSON(nil)
+	}).Name("issues")
+	serve := func(url string) *httptest.ResponseRecorder {
+		pagination = nil
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", url, nil)
+		router.ServeHTTP(res, req)
+		return res
+	}
+
+	res := serve("/projects/7/issues?page=2&per_page=20&sort=name,-created_at&filter[status]=open")
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, 2, pagination.Page)
+	assert.Equal(t, 20, pagination.Limit())
+	assert.Equal(t, 20, pagination.Offset())
+	assert.Equal(t, []SortField{{"name", false}, {"created_at", true}}, pagination.Sort)
+	assert.Equal(t, map[string]string{"status": "open"}, pagination.Filters)
+	assert.Equal(t, "95", res.Header().Get("X-Total-Count"))
+	link := func(page, rel string) string {
+		return `</projects/7/issues?filter%5Bstatus%5D=open&page=` + page + `&per_page=20&sort=name%2C-created_at>; rel="` + rel + `"`
+	}
+	assert.Equal(t, link("1", "first")+", "+link("1", "prev")+", "+link("3", "next")+", "+link("5", "last"), res.Header().Get("Link"))
+
+	res = serve("/projects/7/issues?per_page=500")
+	assert.Equal(t, 1, pagination.Page)
+	assert.Equal(t, 50, pagination.PerPage)
+	assert.Equal(t, []SortField{{"created_at", true}}, pagination.Sort)
+	assert.Equal(t, `</projects/7/issues?page=1&per_page=50>; rel="first", `+
+		`</projects/7/issues?page=2&per_page=50>; rel="next", `+
+		`</projects/7/issues?page=2&per_page=50>; rel="last"`, res.Header().Get("Link"))
+
+	res = serve("/projects/7/issues?page=" + strconv.Itoa(math.MaxInt/50+1) + "&per_page=50")
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, math.MaxInt/50*50, pagination.Offset())
+
+	for _, url := range []string{"?page=0", "?page=x", "?per_page=-1", "?sort=password", "?filter[owner]=me",
+		"?page=" + strconv.Itoa(math.MaxInt/50+2) + "&per_page=50", "?page=" + strconv.Itoa(math.MaxInt)} {
+		res = serve("/projects/7/issues" + url)
+		assert.Equal(t, http.StatusBadRequest, res.Code, url)
+	}
+}
+
+func TestPaginationCursor(t *testing.T) {
+	router := New()
+	router.GET("/feed", func(c *Context) error { return nil }).Name("feed")
+	router.GET("/api/feed", func(c *Context) error {
+		p, err := c.Paginate(PaginationConfig{Route: "feed"})
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, "abc", p.Cursor)
+		p.SetCursorHeaders("def", "")
+		return nil
+	})
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/feed?cursor=abc&per_page=10", nil)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, `</feed?cursor=def&per_page=10>; rel="next"`, res.Header().Get("Link"))
+	assert.Equal(t, "", res.Header().Get("X-Total-Count"))
+}
+
+func TestPaginationRouteParams(t *testing.T) {
+	router := New()
+	router.GET("/projects/<project>/issues", func(c *Context) error { return nil }).Name("project-issues")
+	router.GET("/issues/<id>/related", func(c *Context) error {
+		p, err := c.Paginate(PaginationConfig{Route: "project-issues", RouteParams: []interface{}{"project", 9}, MaxPage: 3})
+		if err != nil {
+			return err
+		}
+		p.SetHeaders(40)
+		return nil
+	})
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/issues/3/related?page=2", nil)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, `</projects/9/issues?page=1>; rel="first", </projects/9/issues?page=1>; rel="prev", `+
+		`</projects/9/issues?page=2>; rel="last"`, res.Header().Get("Link"))
+
+	res = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/issues/3/related?page=4", nil)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+}
@@ -1,35 +1,239 @@
 package tigo
 
 import (
-	"runtime/debug"
-	"io"
+	"bufio"
+	"bytes"
 	"fmt"
-	"time"
+	"html/template"
+	"io"
+	"log/slog"
 	"net/http"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
 )
 
+// PanicConfig specifies how PanicWithConfig reports the panics it recovers from.
+type PanicConfig struct {
+	Writer      io.Writer                                         //text output of the panic info and stack trace
+	Logger      *slog.Logger                                      //structured output of the panic info and stack trace
+	OnPanic     func(c *Context, value interface{}, stack []byte) //callback called for each panic, e.g. to report it
+	Development bool                                              //send a page showing the stack trace with source snippets, request details and route info
+}
+
+// PanicError is the cause of the error returned by the Panic handlers when they recover from a panic.
+type PanicError struct {
+	Value interface{} //value passed to panic
+	Stack []byte      //stack trace of the goroutine which panicked
+}
+
+// Error returns the panic value.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// Panic returns a handler that recovers from panics and writes the panic info to writer.
 func Panic(writer io.Writer) Handler {
-	return func(ctx *Context) (err error){
+	return PanicWithConfig(PanicConfig{Writer: writer})
+}
+
+// PanicWithConfig returns a handler that recovers from the panics of the next handlers and reports them.
+// The panic is converted into a 500 HTTP error whose cause is a *PanicError, so that the panic value
+// is logged but not sent to clients. In development mode, a page showing the stack trace is sent instead.
+// A panic with the value http.ErrAbortHandler, which aborts the response, is not recovered.
+func PanicWithConfig(config PanicConfig) Handler {
+	return func(ctx *Context) (err error) {
 		defer func() {
-			if rerr := recover(); rerr != nil{
-				err = NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("%s", rerr))
-				if writer == nil{
-					return
-				}
-				writer.Write([]byte(fmt.Sprintf(
-					"----------- Tigo panic info start --------------\nError:%v\nTime:%v\nUri:%s\nRemote-Addr:%s\n%s\n%s----------- Tigo panic info end --------------\n",
-					err,
+			rerr := recover()
+			if rerr == nil {
+				return
+			}
+			if rerr == http.ErrAbortHandler {
+				panic(rerr)
+			}
+			stack := debug.Stack()
+			err = NewStatusError(http.StatusInternalServerError).WithCause(&PanicError{Value: rerr, Stack: stack})
+			//abort next
+			ctx.Abort()
+
+			if config.Writer != nil {
+				config.Writer.Write([]byte(fmt.Sprintf(
+					"----------- Tigo panic info start --------------\nError:%v\nTime:%v\nUri:%s\nRequest-ID:%s\nRemote-Addr:%s\n%s\n%s----------- Tigo panic info end --------------\n",
+					rerr,
 					time.Now().Format(time.RFC3339),
 					ctx.Request.URL.RequestURI(),
+					ctx.RequestID(),
 					ctx.Request.RemoteAddr,
 					ctx.Request.Header,
-					debug.Stack(),
+					stack,
 				)))
-				//abort next
-				ctx.Abort()
+			}
+			if config.Logger != nil {
+				config.Logger.Error("panic recovered",
+					slog.Any("error", rerr),
+					slog.String("method", ctx.Request.Method),
+					slog.String("uri", ctx.Request.URL.RequestURI()),
+					slog.String("route", routePath(ctx)),
+					slog.String("request_id", ctx.RequestID()),
+					slog.String("remote_addr", ctx.Request.RemoteAddr),
+					slog.String("stack", string(stack)),
+				)
+			}
+			if config.OnPanic != nil {
+				config.OnPanic(ctx, rerr, stack)
+			}
+			// the error is still returned if the page cannot be sent, so that it is not lost
+			if config.Development && writePanicPage(ctx, rerr, callerFrames()) {
+				err = nil
 			}
 		}()
 		err = ctx.Next()
 		return
 	}
-}
\ No newline at end of file
+}
+
+// routePath returns the path pattern of the route of the request, or an empty string if no route matches.
+func routePath(c *Context) string {
+	if route := c.Route(); route != nil {
+		return route.Path()
+	}
+	return ""
+}
+
+// panicFrame is a stack frame of the page sent in development mode.
+type panicFrame struct {
+	Function string
+	File     string
+	Line     int
+	Source   []panicSourceLine
+}
+
+type panicSourceLine struct {
+	Number  int
+	Text    string
+	Current bool
+}
+
+// callerFrames returns the stack frames of the goroutine from the function which panicked,
+// with a few lines of source code around the call if the source files are available.
+func callerFrames() []panicFrame {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(1, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	var result []panicFrame
+	panicked := false
+	for {
+		frame, more := frames.Next()
+		if panicked {
+			result = append(result, panicFrame{
+				Function: frame.Function,
+				File:     frame.File,
+				Line:     frame.Line,
+				Source:   sourceLines(frame.File, frame.Line, 3),
+			})
+		} else if frame.Function == "runtime.gopanic" {
+			panicked = true
+		}
+		if !more {
+			break
+		}
+	}
+	return result
+}
+
+// sourceLines returns the lines of the file around the given line.
+func sourceLines(file string, line, around int) []panicSourceLine {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var lines []panicSourceLine
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan() && n <= line+around; n++ {
+		if n >= line-around {
+			lines = append(lines, panicSourceLine{n, scanner.Text(), n == line})
+		}
+	}
+	return lines
+}
+
+var panicPageTemplate = template.Must(template.New("panic").Parse(`<!doctype html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>panic: {{.Error}}</title>
+<style>
+body{font:14px/1.4 sans-serif;margin:0 2em 2em}h1{color:#c00;font-size:20px}h2{font-size:16px;margin-top:2em}
+table{border-collapse:collapse}td,th{border-bottom:1px solid #eee;padding:2px 8px;text-align:left;vertical-align:top}
+pre{background:#f6f6f6;margin:4px 0 12px;overflow:auto;padding:4px 0}pre span{display:block;padding:0 8px}
+pre .current{background:#fdd}.frame{font-family:monospace}.file{color:#666}
+</style>
+</head>
+<body>
+<h1>panic: {{.Error}}</h1>
+<h2>Request</h2>
+<table>
+<tr><th>Method</th><td>{{.Method}}</td></tr>
+<tr><th>URI</th><td>{{.URI}}</td></tr>
+<tr><th>Route</th><td>{{.Route}}</td></tr>
+<tr><th>Parameters</th><td>{{range .Params}}{{.}}<br>{{end}}</td></tr>
+<tr><th>Request ID</th><td>{{.RequestID}}</td></tr>
+<tr><th>Remote address</th><td>{{.RemoteAddr}}</td></tr>
+</table>
+<h2>Headers</h2>
+<table>
+{{range .Headers}}<tr><th>{{index . 0}}</th><td>{{index . 1}}</td></tr>
+{{end}}</table>
+<h2>Stack trace</h2>
+{{range .Frames}}<div class="frame">{{.Function}}<br><span class="file">{{.File}}:{{.Line}}</span></div>
+{{if .Source}}<pre>{{range .Source}}<span{{if .Current}} class="current"{{end}}>{{printf "%5d" .Number}}  {{.Text}}</span>{{end}}</pre>
+{{end}}{{end}}</body>
+</html>
+`))
+
+// writePanicPage sends the development page describing the panic, and reports whether it was sent.
+// Nothing is sent if the response is already committed.
+func writePanicPage(c *Context, value interface{}, frames []panicFrame) bool {
+	if c.Written() {
+		return false
+	}
+	var headers [][2]string
+	for name, values := range c.Request.Header {
+		headers = append(headers, [2]string{name, strings.Join(values, ", ")})
+	}
+	sort.Slice(headers, func(i, j int) bool { return headers[i][0] < headers[j][0] })
+	var params []string
+	for i, name := range c.pnames {
+		params = append(params, name+" = "+c.pvalues[i])
+	}
+	buf := new(bytes.Buffer)
+	err := panicPageTemplate.Execute(buf, map[string]interface{}{
+		"Error":      fmt.Sprint(value),
+		"Method":     c.Request.Method,
+		"URI":        c.Request.URL.RequestURI(),
+		"Route":      routePath(c),
+		"Params":     params,
+		"RequestID":  c.RequestID(),
+		"RemoteAddr": c.Request.RemoteAddr,
+		"Headers":    headers,
+		"Frames":     frames,
+	})
+	if err != nil {
+		http.Error(c.Response, fmt.Sprint(value), http.StatusInternalServerError)
+		return true
+	}
+	c.Response.Header().Set("Content-Type", "text/html; charset=utf-8")
+	c.Response.WriteHeader(http.StatusInternalServerError)
+	buf.WriteTo(c.Response)
+	return true
+}
@@ -0,0 +1,113 @@
+package tigo
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPanic(t *testing.T) {
+	var logs bytes.Buffer
+	var handled error
+	router := New()
+	router.OnError = func(c *Context, err error) {
+		handled = err
+		DefaultErrorHandler(c, err)
+	}
+	router.Use(Panic(&logs))
+	router.GET("/users/<id>", func(c *Context) error {
+		panic("db password is secret")
+	})
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/users/1", nil)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusInternalServerError, res.Code)
+	assert.NotContains(t, res.Body.String(), "secret")
+	assert.Contains(t, logs.String(), "Error:db password is secret")
+	assert.Contains(t, logs.String(), "Uri:/users/1")
+
+	var panicError *PanicError
+	if assert.True(t, errors.As(handled, &panicError)) {
+		assert.Equal(t, "db password is secret", panicError.Value)
+		assert.Contains(t, string(panicError.Stack), "panic_test.go")
+	}
+}
+
+func TestPanicWithConfig(t *testing.T) {
+	var logs bytes.Buffer
+	var value interface{}
+	router := New()
+	router.Use(PanicWithConfig(PanicConfig{
+		Logger: slog.New(slog.NewJSONHandler(&logs, nil)),
+		OnPanic: func(c *Context, v interface{}, stack []byte) {
+			value = v
+		},
+	}))
+	errBoom := errors.New("boom")
+	router.GET("/users/<id>", func(c *Context) error {
+		panic(errBoom)
+	})
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/users/1", nil)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusInternalServerError, res.Code)
+	assert.Equal(t, errBoom, value)
+	assert.Contains(t, logs.String(), `"msg":"panic recovered"`)
+	assert.Contains(t, logs.String(), `"error":"boom"`)
+	assert.Contains(t, logs.String(), `"route":"/users/<id>"`)
+	assert.Contains(t, logs.String(), `"stack":"goroutine`)
+
+	router.GET("/abort", func(c *Context) error {
+		panic(http.ErrAbortHandler)
+	})
+	req, _ = http.NewRequest("GET", "/abort", nil)
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	})
+}
+
+func TestPanicDevelopment(t *testing.T) {
+	router := New()
+	router.Use(PanicWithConfig(PanicConfig{Development: true}))
+	router.GET("/users/<id>", func(c *Context) error {
+		var users map[string]string
+		users[c.Param("id")] = "<b>"
+		return nil
+	})
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/users/1", nil)
+	req.Header.Set("X-Test", "<value>")
+	router.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusInternalServerError, res.Code)
+	assert.Equal(t, "text/html; charset=utf-8", res.Header().Get("Content-Type"))
+	body := res.Body.String()
+	assert.Contains(t, body, "panic: assignment to entry in nil map")
+	assert.Contains(t, body, "/users/&lt;id&gt;")
+	assert.Contains(t, body, "id = 1")
+	assert.Contains(t, body, "X-Test")
+	assert.Contains(t, body, "&lt;value&gt;")
+	assert.Contains(t, body, "TestPanicDevelopment")
+	assert.Contains(t, body, `class="current">`)
+	assert.Contains(t, body, `users[c.Param(&#34;id&#34;)] = &#34;&lt;b&gt;&#34;`)
+}
+
+func TestPanicDevelopmentCommitted(t *testing.T) {
+	h := PanicWithConfig(PanicConfig{Development: true})
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/stream", nil)
+	c := NewContext(res, req, h, func(c *Context) error {
+		c.Text("partial")
+		panic("stream failed")
+	})
+	err := c.Next()
+	var pe *PanicError
+	if assert.True(t, errors.As(err, &pe)) {
+		assert.Equal(t, "stream failed", pe.Value)
+	}
+	assert.Equal(t, "partial", res.Body.String())
+}
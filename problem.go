@@ -0,0 +1,237 @@
+package tigo
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// ProblemDetails describes an error as specified by RFC 7807.
+// It can be returned by handlers as an HTTPError, and it is the data of the HTML error pages
+// rendered by DefaultErrorHandler.
+type ProblemDetails struct {
+	Type       string                 //URI identifying the problem type, "about:blank" if empty
+	Title      string                 //short summary of the problem type
+	Status     int                    //HTTP status code
+	Detail     string                 //explanation specific to this occurrence of the problem
+	Instance   string                 //URI identifying this occurrence of the problem
+	Extensions map[string]interface{} //additional members
+}
+
+// NewProblemDetails creates the problem details of an error returned by a handler of the request.
+// The public message of an HTTPError is used as the detail, and the code and details of a StatusError
+// are added as the "code" and "details" extension members. The message of other errors is not disclosed.
+func NewProblemDetails(c *Context, err error) *ProblemDetails {
+	var p *ProblemDetails
+	if errors.As(err, &p) {
+		problem := *p
+		if problem.Instance == "" && c.Request != nil {
+			problem.Instance = c.Request.URL.RequestURI()
+		}
+		return &problem
+	}
+	status := statusCodeOf(err, http.StatusInternalServerError)
+	problem := &ProblemDetails{Title: http.StatusText(status), Status: status}
+	var httpError HTTPError
+	if errors.As(err, &httpError) {
+		if message := publicMessage(httpError); message != problem.Title {
+			problem.Detail = message
+		}
+	}
+	var statusError *StatusError
+	if errors.As(err, &statusError) && (statusError.Code != "" || statusError.Details != nil) {
+		problem.Extensions = make(map[string]interface{})
+		if statusError.Code != "" {
+			problem.Extensions["code"] = statusError.Code
+		}
+		if statusError.Details != nil {
+			problem.Extensions["details"] = statusError.Details
+		}
+	}
+	if c.Request != nil {
+		problem.Instance = c.Request.URL.RequestURI()
+	}
+	return problem
+}
+
+// Error returns the detail of the problem, or its title.
+func (p *ProblemDetails) Error() string {
+	if p.Detail != "" {
+		return p.Detail
+	}
+	if p.Title != "" {
+		return p.Title
+	}
+	return http.StatusText(p.StatusCode())
+}
+
+// StatusCode returns the HTTP status code.
+func (p *ProblemDetails) StatusCode() int {
+	if p.Status == 0 {
+		return http.StatusInternalServerError
+	}
+	return p.Status
+}
+
+// MarshalJSON encodes the problem as an RFC 7807 JSON object, with the extensions as top-level members.
+func (p *ProblemDetails) MarshalJSON() ([]byte, error) {
+	members := make(map[string]interface{}, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		members[k] = v
+	}
+	members["type"] = p.typeURI()
+	members["status"] = p.StatusCode()
+	if p.Title != "" {
+		members["title"] = p.Title
+	}
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+	return json.Marshal(members)
+}
+
+// MarshalXML encodes the problem as an RFC 7807 XML document, with the extensions as child elements.
+func (p *ProblemDetails) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{
+		Name: xml.Name{Local: "problem"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: "urn:ietf:rfc:7807"}},
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	elements := []struct {
+		name  string
+		value interface{}
+	}{
+		{"type", p.typeURI()},
+		{"title", p.Title},
+		{"status", p.StatusCode()},
+		{"detail", p.Detail},
+		{"instance", p.Instance},
+	}
+	keys := make([]string, 0, len(p.Extensions))
+	for k := range p.Extensions {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		elements = append(elements, struct {
+			name  string
+			value interface{}
+		}{k, p.Extensions[k]})
+	}
+	for _, element := range elements {
+		if s, ok := element.value.(string); ok && s == "" {
+			continue
+		}
+		if err := e.EncodeElement(element.value, xml.StartElement{Name: xml.Name{Local: element.name}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (p *ProblemDetails) typeURI() string {
+	if p.Type == "" {
+		return "about:blank"
+	}
+	return p.Type
+}
+
+// DefaultErrorHandler sends the error in the format preferred by the client according to its Accept header:
+// RFC 7807 problem details in JSON or XML, an HTML page, or plain text by default.
+// The HTML page is rendered with the template Router.ErrorTemplate if it is set, and given a *ProblemDetails.
+// Nothing is sent if the response is already committed.
+func DefaultErrorHandler(c *Context, err error) {
+	if c.Written() {
+		return
+	}
+	problem := NewProblemDetails(c, err)
+	accept := ""
+	if c.Request != nil {
+		accept = c.Request.Header.Get("Accept")
+	}
+	h := c.Response.Header()
+	switch negotiateContentType(accept, MIME_TEXT, MIME_HTML, MIME_PROBLEM_JSON, MIME_JSON, MIME_PROBLEM_XML, MIME_XML, MIME_XML2) {
+	case MIME_PROBLEM_JSON, MIME_JSON:
+		data, err := json.Marshal(problem)
+		if err != nil {
+			break
+		}
+		h.Set("Content-Type", MIME_PROBLEM_JSON)
+		h.Set("X-Content-Type-Options", "nosniff")
+		c.Response.WriteHeader(problem.StatusCode())
+		c.Response.Write(data)
+		return
+	case MIME_PROBLEM_XML, MIME_XML, MIME_XML2:
+		data, err := xml.Marshal(problem)
+		if err != nil {
+			break
+		}
+		h.Set("Content-Type", MIME_PROBLEM_XML)
+		h.Set("X-Content-Type-Options", "nosniff")
+		c.Response.WriteHeader(problem.StatusCode())
+		c.Response.Write([]byte(xml.Header))
+		c.Response.Write(data)
+		return
+	case MIME_HTML:
+		if c.router != nil && c.router.Render != nil && c.router.ErrorTemplate != "" && c.Request != nil {
+			if c.RenderStatus(problem.StatusCode(), c.router.ErrorTemplate, problem) == nil {
+				return
+			}
+		}
+		buf := new(bytes.Buffer)
+		title := fmt.Sprintf("%d %s", problem.StatusCode(), html.EscapeString(problem.Title))
+		fmt.Fprintf(buf, "<!doctype html>\n<meta charset=\"utf-8\">\n<title>%s</title>\n<h1>%s</h1>\n", title, title)
+		if problem.Detail != "" {
+			fmt.Fprintf(buf, "<p>%s</p>\n", html.EscapeString(problem.Detail))
+		}
+		h.Set("Content-Type", "text/html; charset=utf-8")
+		h.Set("X-Content-Type-Options", "nosniff")
+		c.Response.WriteHeader(problem.StatusCode())
+		buf.WriteTo(c.Response)
+		return
+	}
+	http.Error(c.Response, problem.Error(), problem.StatusCode())
+}
+
+// negotiateContentType returns the offer preferred by the Accept header value, or an empty string
+// if the header is empty or accepts none of the offers. An offer matching a media range exactly
+// is preferred over an offer matching a wildcard with the same quality, then the first offer is preferred.
+func negotiateContentType(accept string, offers ...string) string {
+	if strings.TrimSpace(accept) == "" {
+		return ""
+	}
+	best, bestQ, bestSpecificity := "", 0.0, -1
+	for _, offer := range offers {
+		q, specificity := 0.0, -1
+		for _, part := range strings.Split(accept, ",") {
+			mediaRange, quality := parseQualityValue(part)
+			s := -1
+			switch {
+			case mediaRange == offer:
+				s = 2
+			case strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(offer, mediaRange[:len(mediaRange)-1]):
+				s = 1
+			case mediaRange == "*/*" || mediaRange == "*":
+				s = 0
+			}
+			if s > specificity {
+				q, specificity = quality, s
+			}
+		}
+		if q > 0 && (q > bestQ || q == bestQ && specificity > bestSpecificity) {
+			best, bestQ, bestSpecificity = offer, q, specificity
+		}
+	}
+	return best
+}
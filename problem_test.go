@@ -0,0 +1,78 @@
+package tigo
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultErrorHandler(t *testing.T) {
+	router := New()
+	router.GET("/users/<id>", func(c *Context) error {
+		return NewHTTPError(http.StatusNotFound, "User "+c.Param("id")+" not found")
+	})
+	router.GET("/internal", func(c *Context) error { return errors.New("database password is wrong") })
+	router.GET("/problem", func(c *Context) error {
+		return &ProblemDetails{
+			Type:       "https://example.com/probs/out-of-credit",
+			Title:      "You do not have enough credit.",
+			Status:     http.StatusForbidden,
+			Extensions: map[string]interface{}{"balance": 30},
+		}
+	})
+
+	tests := []struct {
+		id, url, accept string
+		status          int
+		contentType     string
+		body            string
+	}{
+		{"text", "/users/1", "", 404, "text/plain; charset=utf-8", "User 1 not found\n"},
+		{"any", "/users/1", "*/*", 404, "text/plain; charset=utf-8", "User 1 not found\n"},
+		{"json", "/users/1", "application/json", 404, MIME_PROBLEM_JSON, `{"detail":"User 1 not found","instance":"/users/1","status":404,"title":"Not Found","type":"about:blank"}`},
+		{"problem json", "/users/1?x=1", "application/problem+json, */*;q=0.1", 404, MIME_PROBLEM_JSON, `{"detail":"User 1 not found","instance":"/users/1?x=1","status":404,"title":"Not Found","type":"about:blank"}`},
+		{"xml", "/users/1", "text/xml", 404, MIME_PROBLEM_XML, xml.Header + `<problem xmlns="urn:ietf:rfc:7807"><type>about:blank</type><title>Not Found</title><status>404</status><detail>User 1 not found</detail><instance>/users/1</instance></problem>`},
+		{"html", "/users/<b>", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", 404, "text/html; charset=utf-8", "<!doctype html>\n<meta charset=\"utf-8\">\n<title>404 Not Found</title>\n<h1>404 Not Found</h1>\n<p>User &lt;b&gt; not found</p>\n"},
+		{"internal", "/internal", "application/json", 500, MIME_PROBLEM_JSON, `{"instance":"/internal","status":500,"title":"Internal Server Error","type":"about:blank"}`},
+		{"internal text", "/internal", "", 500, "text/plain; charset=utf-8", "Internal Server Error\n"},
+		{"extensions", "/problem", "application/json", 403, MIME_PROBLEM_JSON, `{"balance":30,"instance":"/problem","status":403,"title":"You do not have enough credit.","type":"https://example.com/probs/out-of-credit"}`},
+		{"not found route", "/none", "application/json", 404, MIME_PROBLEM_JSON, `{"instance":"/none","status":404,"title":"Not Found","type":"about:blank"}`},
+	}
+	for _, test := range tests {
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", test.url, nil)
+		req.Header.Set("Accept", test.accept)
+		router.ServeHTTP(res, req)
+		assert.Equal(t, test.status, res.Code, test.id)
+		assert.Equal(t, test.contentType, res.Header().Get("Content-Type"), test.id)
+		assert.Equal(t, test.body, res.Body.String(), test.id)
+	}
+
+	router.Render = NewViewRender(ViewRenderConfig{
+		FileSystem: fstest.MapFS{"error.html": {Data: []byte(`<h1>{{.Status}}</h1><p>{{.Detail}}</p>`)}},
+		Extension:  ".html",
+	})
+	router.ErrorTemplate = "error"
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/users/2", nil)
+	req.Header.Set("Accept", "text/html")
+	router.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusNotFound, res.Code)
+	assert.Equal(t, "<h1>404</h1><p>User 2 not found</p>", res.Body.String())
+}
+
+func TestNegotiateContentType(t *testing.T) {
+	offers := []string{"text/plain", "text/html", "application/json"}
+	assert.Equal(t, "", negotiateContentType("", offers...))
+	assert.Equal(t, "text/plain", negotiateContentType("*/*", offers...))
+	assert.Equal(t, "application/json", negotiateContentType("application/json, */*", offers...))
+	assert.Equal(t, "text/html", negotiateContentType("text/*;q=0.5, text/html", offers...))
+	assert.Equal(t, "text/plain", negotiateContentType("text/*, text/html;q=0.5", offers...))
+	assert.Equal(t, "", negotiateContentType("image/png", offers...))
+	assert.Equal(t, "", negotiateContentType("application/json;q=0", offers...))
+}
@@ -0,0 +1,118 @@
+package tigo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueryValues returns the query parameters of the request. They are parsed once per request,
+// so changes of Request.URL.RawQuery made afterwards are not reflected.
+func (c *Context) QueryValues() url.Values {
+	if c.query == nil {
+		c.query, _ = url.ParseQuery(c.Request.URL.RawQuery)
+	}
+	return c.query
+}
+
+// QueryAll returns all the values of the named query parameter, e.g. ["a", "b"] for "?tag=a&tag=b".
+func (c *Context) QueryAll(name string) []string {
+	return c.QueryValues()[name]
+}
+
+// QueryMap returns the query parameters named with the bracket syntax, e.g. {"status": "open"} for
+// "?filter[status]=open" and the name "filter". Only the first value of each parameter is returned.
+func (c *Context) QueryMap(name string) map[string]string {
+	result := make(map[string]string)
+	prefix := name + "["
+	for key, values := range c.QueryValues() {
+		if len(values) > 0 && strings.HasPrefix(key, prefix) && strings.HasSuffix(key, "]") && len(key) > len(prefix)+1 {
+			result[key[len(prefix):len(key)-1]] = values[0]
+		}
+	}
+	return result
+}
+
+// QueryInt returns the named query parameter as an int.
+// If it is not present or empty, it returns the specified default value or 0.
+// A 400 HTTP error is returned if it is not an integer.
+func (c *Context) QueryInt(name string, defaultValue ...int) (int, error) {
+	value := c.Query(name)
+	if value == "" {
+		if len(defaultValue) > 0 {
+			return defaultValue[0], nil
+		}
+		return 0, nil
+	}
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, invalidQueryError(name, "an integer", err)
+	}
+	return v, nil
+}
+
+// QueryBool returns the named query parameter as a bool, accepting the values of strconv.ParseBool.
+// If it is not present or empty, it returns the specified default value or false.
+// A 400 HTTP error is returned if it is not a boolean.
+func (c *Context) QueryBool(name string, defaultValue ...bool) (bool, error) {
+	value := c.Query(name)
+	if value == "" {
+		if len(defaultValue) > 0 {
+			return defaultValue[0], nil
+		}
+		return false, nil
+	}
+	v, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, invalidQueryError(name, "a boolean", err)
+	}
+	return v, nil
+}
+
+// QueryTime returns the named query parameter as a time in the given layout, or in RFC 3339 if layout is empty.
+// If it is not present or empty, it returns the specified default value or the zero time.
+// A 400 HTTP error is returned if it is not a time in the layout.
+func (c *Context) QueryTime(name string, layout string, defaultValue ...time.Time) (time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		if len(defaultValue) > 0 {
+			return defaultValue[0], nil
+		}
+		return time.Time{}, nil
+	}
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	v, err := time.Parse(layout, value)
+	if err != nil {
+		return time.Time{}, invalidQueryError(name, "a time in the format "+layout, err)
+	}
+	return v, nil
+}
+
+// BindQuery populates the struct pointed to by data with the query parameters, as ReadFormData does,
+// whatever the request method and content type are. A 400 HTTP error naming every invalid parameter
+// is returned if values cannot be converted to their fields.
+func (c *Context) BindQuery(data interface{}) error {
+	err := ReadFormData(c.QueryValues(), data)
+	var fieldErrors formFieldErrors
+	if !errors.As(err, &fieldErrors) {
+		return err
+	}
+	if len(fieldErrors) == 1 {
+		return NewStatusError(http.StatusBadRequest, fmt.Sprintf("Query parameter %v is invalid", fieldErrors[0].name)).WithCause(err)
+	}
+	names := make([]string, len(fieldErrors))
+	for i, fieldError := range fieldErrors {
+		names[i] = fieldError.name
+	}
+	return NewStatusError(http.StatusBadRequest, fmt.Sprintf("Query parameters %v are invalid", strings.Join(names, ", "))).WithCause(err)
+}
+
+func invalidQueryError(name, expected string, err error) error {
+	return NewStatusError(http.StatusBadRequest, fmt.Sprintf("Query parameter %v must be %v", name, expected)).WithCause(err)
+}
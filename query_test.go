@@ -0,0 +1,91 @@
+package tigo
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextQuery(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/issues?tag=a&tag=b&filter[status]=open&filter[owner]=me&filter[]=x&filterx=y&page=2&draft=true&since=2024-05-01", nil)
+	c := NewContext(nil, req)
+	assert.Equal(t, []string{"a", "b"}, c.QueryAll("tag"))
+	assert.Nil(t, c.QueryAll("missing"))
+	assert.Equal(t, map[string]string{"status": "open", "owner": "me"}, c.QueryMap("filter"))
+	assert.Equal(t, map[string]string{}, c.QueryMap("sort"))
+
+	page, err := c.QueryInt("page")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, page)
+	perPage, err := c.QueryInt("per_page", 20)
+	assert.Nil(t, err)
+	assert.Equal(t, 20, perPage)
+	_, err = c.QueryInt("tag")
+	assert.Equal(t, http.StatusBadRequest, statusCodeOf(err, 0))
+	assert.Equal(t, "Query parameter tag must be an integer", err.(*StatusError).PublicMessage())
+
+	draft, err := c.QueryBool("draft")
+	assert.Nil(t, err)
+	assert.True(t, draft)
+	_, err = c.QueryBool("tag")
+	assert.Equal(t, http.StatusBadRequest, statusCodeOf(err, 0))
+
+	since, err := c.QueryTime("since", "2006-01-02")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), since)
+	_, err = c.QueryTime("since", "")
+	assert.Equal(t, http.StatusBadRequest, statusCodeOf(err, 0))
+
+	// the query is parsed once per request
+	req.URL.RawQuery = "page=3"
+	assert.Equal(t, "2", c.Query("page"))
+	c.init(nil, req)
+	assert.Equal(t, "3", c.Query("page"))
+}
+
+func TestContextBindQuery(t *testing.T) {
+	type filter struct {
+		Status string   `form:"status"`
+		Tags   []string `form:"tag"`
+		Page   int      `form:"page"`
+	}
+	req, _ := http.NewRequest("POST", "/issues?status=open&tag=a&tag=b&page=2", nil)
+	req.Header.Set("Content-Type", MIME_JSON)
+	c := NewContext(nil, req)
+	var f filter
+	assert.Nil(t, c.BindQuery(&f))
+	assert.Equal(t, filter{"open", []string{"a", "b"}, 2}, f)
+
+	req, _ = http.NewRequest("GET", "/issues?page=two", nil)
+	c = NewContext(nil, req)
+	err := c.BindQuery(&f)
+	assert.Equal(t, http.StatusBadRequest, statusCodeOf(err, 0))
+	assert.Equal(t, "Query parameter page is invalid", err.(*StatusError).PublicMessage())
+
+	var g struct {
+		Page   int `form:"page"`
+		Filter struct {
+			Owner int `form:"owner"`
+		} `form:"filter"`
+		Draft   bool `form:"draft"`
+		PerPage int  `form:"per_page"`
+	}
+	req, _ = http.NewRequest("GET", "/issues?page=two&filter.owner=me&draft=true&per_page=x", nil)
+	c = NewContext(nil, req)
+	err = c.BindQuery(&g)
+	assert.Equal(t, http.StatusBadRequest, statusCodeOf(err, 0))
+	assert.Equal(t, "Query parameters page, filter.owner, per_page are invalid", err.(*StatusError).PublicMessage())
+	assert.True(t, g.Draft)
+	assert.NotNil(t, c.BindQuery(f))
+
+	var unsupported struct {
+		Point complex128 `form:"point"`
+	}
+	req, _ = http.NewRequest("GET", "/issues?point=abc", nil)
+	c = NewContext(nil, req)
+	err = c.BindQuery(&unsupported)
+	assert.Equal(t, http.StatusBadRequest, statusCodeOf(err, 0))
+	assert.Equal(t, "Query parameter point is invalid", err.(*StatusError).PublicMessage())
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"reflect"
 	"strconv"
+	"strings"
 )
 
 // MIME types used when doing request data reading and response data writing.
@@ -17,6 +18,9 @@ const (
 	MIME_HTML           = "text/html"
 	MIME_FORM           = "application/x-www-form-urlencoded"
 	MIME_MULTIPART_FORM = "multipart/form-data"
+	MIME_PROBLEM_JSON   = "application/problem+json"
+	MIME_PROBLEM_XML    = "application/problem+xml"
+	MIME_TEXT           = "text/plain"
 )
 
 // DataReader is used by Context.Read() to read data from an HTTP request.
@@ -78,10 +82,15 @@ func ReadFormData(form map[string][]string, data interface{}) error {
 		return errors.New("data must be a pointer to a struct")
 	}
 
-	return readForm(form, "", rv)
+	if errs := readForm(form, "", rv, nil); len(errs) > 0 {
+		return errs
+	}
+	return nil
 }
 
-func readForm(form map[string][]string, prefix string, rv reflect.Value) error {
+// readForm sets the fields of rv from the form values and returns errs with the errors of the fields
+// which could not be set appended, so that every invalid field is reported.
+func readForm(form map[string][]string, prefix string, rv reflect.Value, errs formFieldErrors) formFieldErrors {
 	rv = indirect(rv)
 	rt := rv.Type()
 	n := rt.NumField()
@@ -109,7 +118,7 @@ func readForm(form map[string][]string, prefix string, rv reflect.Value) error {
 
 		if ft.Kind() != reflect.Struct {
 			if err := readFormField(form, name, rv.Field(i)); err != nil {
-				return err
+				errs = append(errs, err)
 			}
 			continue
 		}
@@ -117,34 +126,68 @@ func readForm(form map[string][]string, prefix string, rv reflect.Value) error {
 		if name == "" {
 			name = prefix
 		}
-		if err := readForm(form, name, rv.Field(i)); err != nil {
-			return err
-		}
+		errs = readForm(form, name, rv.Field(i), errs)
 	}
-	return nil
+	return errs
 }
 
-func readFormField(form map[string][]string, name string, rv reflect.Value) error {
+func readFormField(form map[string][]string, name string, rv reflect.Value) *formFieldError {
 	value, ok := form[name]
 	if !ok {
 		return nil
 	}
 	rv = indirect(rv)
 	if rv.Kind() != reflect.Slice {
-		return setFormFieldValue(rv, value[0])
+		if err := setFormFieldValue(rv, value[0]); err != nil {
+			return &formFieldError{name, err}
+		}
+		return nil
 	}
 
 	n := len(value)
 	slice := reflect.MakeSlice(rv.Type(), n, n)
 	for i := 0; i < n; i++ {
 		if err := setFormFieldValue(slice.Index(i), value[i]); err != nil {
-			return err
+			return &formFieldError{name, err}
 		}
 	}
 	rv.Set(slice)
 	return nil
 }
 
+// formFieldError is the error of setting a field from the value of the named form field.
+type formFieldError struct {
+	name string
+	err  error
+}
+
+func (e *formFieldError) Error() string {
+	return e.err.Error()
+}
+
+func (e *formFieldError) Unwrap() error {
+	return e.err
+}
+
+// formFieldErrors are the errors of the form fields which could not be set.
+type formFieldErrors []*formFieldError
+
+func (e formFieldErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e formFieldErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
 func setFormFieldValue(rv reflect.Value, value string) error {
 	switch rv.Kind() {
 	case reflect.Bool:
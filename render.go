@@ -1,6 +1,7 @@
 package tigo
 
 import (
+	"html/template"
 	"io"
 )
 //Map for data with map[string]interface{}
@@ -15,3 +16,51 @@ type Render interface {
 	RenderFile(out io.Writer, name string, data interface{}) error
 }
 
+// FuncsRender is implemented by a Render whose templates can call functions bound to the current request.
+// Context.Render and Context.RenderFile use it to pass the request-aware functions registered with RegisterContextFunc.
+type FuncsRender interface {
+	//Render name with the extra template functions, using the master layout if useMaster is true.
+	RenderFuncs(out io.Writer, name string, data interface{}, useMaster bool, funcs template.FuncMap) error
+}
+
+// LayoutRender is implemented by a Render that can render a template within any layout, instead of its master.
+// Context.RenderWithLayout and the routes of a RouteGroup with a layout require it.
+type LayoutRender interface {
+	//Render name within layout with the extra template functions, or alone if layout is empty.
+	RenderLayout(out io.Writer, name string, layout string, data interface{}, funcs template.FuncMap) error
+}
+
+// BlockRender is implemented by a Render that can render a single block defined by a template.
+// Context.RenderBlock and Context.RenderFragment require it.
+type BlockRender interface {
+	//Render the block defined by the template name, or by the layouts it is rendered within,
+	//with the extra template functions. The layout is the one given to RenderLayout, empty for the default one.
+	RenderBlock(out io.Writer, name string, layout string, block string, data interface{}, funcs template.FuncMap) error
+}
+
+// ContentTypeRender is implemented by a Render whose templates may render other content than HTML.
+// Context uses the content type it returns for a template, or text/html if it is empty.
+type ContentTypeRender interface {
+	//Content type of the rendered template name, such as "text/plain; charset=utf-8".
+	ContentType(name string) string
+}
+
+// RenderFunc adapts a function rendering the named template to the Render interface,
+// so that third-party template engines can be used as a Render or registered in a MultiRender.
+// The function renders both Render and RenderFile calls.
+type RenderFunc func(out io.Writer, name string, data interface{}) error
+
+// Init does nothing, the engine wrapped by the function is expected to be initialized.
+func (f RenderFunc) Init() error {
+	return nil
+}
+
+// Render calls f(out, name, data).
+func (f RenderFunc) Render(out io.Writer, name string, data interface{}) error {
+	return f(out, name, data)
+}
+
+// RenderFile calls f(out, name, data).
+func (f RenderFunc) RenderFile(out io.Writer, name string, data interface{}) error {
+	return f(out, name, data)
+}
@@ -0,0 +1,55 @@
+package tigo
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// RequestIDKey is the key used to store and retrieve the request ID in the Context.
+const RequestIDKey = "RequestID"
+
+// RequestIDHeader is the header used to receive and send request IDs.
+var RequestIDHeader = "X-Request-ID"
+
+// RequestID returns a handler that assigns an ID to every request.
+// A valid ID sent by the client in RequestIDHeader is kept, otherwise a random one is generated.
+// The ID is stored in the context, echoed in the response header, and included by Logger and Panic.
+func RequestID() Handler {
+	return func(c *Context) error {
+		id := c.Request.Header.Get(RequestIDHeader)
+		if !isValidRequestID(id) {
+			id = newRandomHex(16)
+		}
+		c.Set(RequestIDKey, id)
+		c.Response.Header().Set(RequestIDHeader, id)
+		return nil
+	}
+}
+
+// RequestID returns the ID assigned to the current request by the RequestID handler.
+// An empty string is returned if no ID has been assigned.
+func (c *Context) RequestID() string {
+	id, _ := c.Get(RequestIDKey).(string)
+	return id
+}
+
+// isValidRequestID accepts IDs of up to 128 printable ASCII characters, so that
+// client-supplied values cannot inject content into headers or log lines.
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e || id[i] == '"' || id[i] == '\\' {
+			return false
+		}
+	}
+	return true
+}
+
+// newRandomHex returns n random bytes encoded as a lowercase hex string.
+func newRandomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,33 @@
+package tigo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	h := RequestID()
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/users/", nil)
+	c := NewContext(res, req)
+	assert.Equal(t, "", c.RequestID())
+	assert.Nil(t, h(c))
+	assert.Equal(t, 32, len(c.RequestID()))
+	assert.Equal(t, c.RequestID(), res.Header().Get("X-Request-ID"))
+
+	req.Header.Set("X-Request-ID", "abc-123")
+	res = httptest.NewRecorder()
+	c = NewContext(res, req)
+	assert.Nil(t, h(c))
+	assert.Equal(t, "abc-123", c.RequestID())
+	assert.Equal(t, "abc-123", res.Header().Get("X-Request-ID"))
+
+	req.Header.Set("X-Request-ID", "abc 123\"")
+	res = httptest.NewRecorder()
+	c = NewContext(res, req)
+	assert.Nil(t, h(c))
+	assert.NotEqual(t, "abc 123\"", c.RequestID())
+}
@@ -0,0 +1,122 @@
+package tigo
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// Response wraps the http.ResponseWriter of a request in order to track the status code and the size
+// of the response, and to call hooks before the response is committed and after data is written.
+// It is the Response of every Context, and it keeps the http.Flusher, http.Hijacker and http.Pusher
+// interfaces of the wrapped writer.
+type Response struct {
+	http.ResponseWriter
+	status      int
+	size        int64
+	committed   bool
+	beforeWrite []func()
+	afterWrite  []func(data []byte)
+}
+
+// reset makes the response wrap the given writer and clears its state.
+func (r *Response) reset(writer http.ResponseWriter) {
+	r.ResponseWriter = writer
+	r.status = http.StatusOK
+	r.size = 0
+	r.committed = false
+	r.beforeWrite = nil
+	r.afterWrite = nil
+}
+
+// WriteHeader sends the headers with the status code, after calling the before-write hooks.
+// Once the response is committed, further calls are ignored, except for informational status codes.
+func (r *Response) WriteHeader(code int) {
+	if r.committed {
+		return
+	}
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		r.ResponseWriter.WriteHeader(code)
+		return
+	}
+	for _, hook := range r.beforeWrite {
+		hook()
+	}
+	r.status = code
+	r.committed = true
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// Write writes data to the response, committing it with the status code set with Context.Status,
+// or 200, if needed, and calls the after-write hooks with the written data.
+func (r *Response) Write(data []byte) (int, error) {
+	if !r.committed {
+		r.WriteHeader(r.status)
+	}
+	n, err := r.ResponseWriter.Write(data)
+	r.size += int64(n)
+	for _, hook := range r.afterWrite {
+		hook(data[:n])
+	}
+	return n, err
+}
+
+// Status returns the status code sent, or the status code to send if the response is not committed yet.
+func (r *Response) Status() int {
+	return r.status
+}
+
+// setStatus sets the status code sent when the response is committed by Write.
+func (r *Response) setStatus(code int) {
+	if !r.committed {
+		r.status = code
+	}
+}
+
+// Size returns the number of bytes of the response body written so far.
+func (r *Response) Size() int64 {
+	return r.size
+}
+
+// Committed returns whether the headers have been sent, after which the status code and headers cannot change.
+func (r *Response) Committed() bool {
+	return r.committed
+}
+
+// Flush commits the response and sends the buffered data to the client, if the wrapped writer supports it.
+func (r *Response) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		if !r.committed {
+			r.WriteHeader(r.status)
+		}
+		flusher.Flush()
+	}
+}
+
+// Hijack lets the caller take over the connection, if the wrapped writer supports it.
+// The response is then considered committed.
+func (r *Response) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the response writer does not implement http.Hijacker")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err == nil {
+		r.committed = true
+	}
+	return conn, rw, err
+}
+
+// Push initiates an HTTP/2 server push, if the wrapped writer supports it.
+func (r *Response) Push(target string, opts *http.PushOptions) error {
+	if pusher, ok := r.ResponseWriter.(http.Pusher); ok {
+		return pusher.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+// Unwrap returns the wrapped writer, for http.ResponseController.
+func (r *Response) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,94 @@
+package tigo
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextResponseState(t *testing.T) {
+	var before int
+	var written bytes.Buffer
+	router := New()
+	router.GET("/users", func(c *Context) error {
+		assert.False(t, c.Written())
+		assert.Equal(t, http.StatusOK, c.StatusCode())
+		c.BeforeWrite(func() {
+			before++
+			c.Response.Header().Set("X-Size", "before")
+		})
+		c.AfterWrite(func(data []byte) {
+			written.Write(data)
+		})
+		c.Response.WriteHeader(http.StatusCreated)
+		c.Response.WriteHeader(http.StatusAccepted)
+		c.Text("hello")
+		c.Text(" world")
+		assert.True(t, c.Written())
+		assert.Equal(t, http.StatusCreated, c.StatusCode())
+		assert.Equal(t, int64(11), c.Size())
+		return nil
+	})
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/users", nil)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusCreated, res.Code)
+	assert.Equal(t, "hello world", res.Body.String())
+	assert.Equal(t, 1, before)
+	assert.Equal(t, "before", res.Header().Get("X-Size"))
+	assert.Equal(t, "hello world", written.String())
+}
+
+func TestContextResponseCommitted(t *testing.T) {
+	var logs bytes.Buffer
+	handled := false
+	router := New()
+	router.Use(LoggerWithConfig(LoggerConfig{Writer: &logs}))
+	router.HandleStatus(http.StatusInternalServerError, func(c *Context, err error) {
+		handled = true
+	})
+	router.GET("/stream", func(c *Context) error {
+		c.Text("partial")
+		return errors.New("connection lost")
+	})
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/stream", nil)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "partial", res.Body.String())
+	assert.False(t, handled)
+	assert.Contains(t, logs.String(), `"status":"200"`)
+	assert.Contains(t, logs.String(), `"response_length":"7"`)
+	assert.Contains(t, logs.String(), `"error":"connection lost"`)
+}
+
+func TestResponseInterfaces(t *testing.T) {
+	router := New()
+	router.GET("/flush", func(c *Context) error {
+		c.Response.Header().Set("Content-Type", "text/event-stream")
+		c.Response.(http.Flusher).Flush()
+		assert.True(t, c.Written())
+		assert.NoError(t, http.NewResponseController(c.Response).Flush())
+		_, _, err := c.Response.(http.Hijacker).Hijack()
+		assert.Error(t, err)
+		assert.Equal(t, http.ErrNotSupported, c.Response.(http.Pusher).Push("/app.js", nil))
+		return nil
+	})
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/flush", nil)
+	router.ServeHTTP(res, req)
+	assert.True(t, res.Flushed)
+	assert.Equal(t, "text/event-stream", res.Header().Get("Content-Type"))
+
+	var response Response
+	response.reset(httptest.NewRecorder())
+	response.WriteHeader(http.StatusEarlyHints)
+	assert.False(t, response.Committed())
+	response.WriteHeader(http.StatusNoContent)
+	assert.True(t, response.Committed())
+	assert.Equal(t, http.StatusNoContent, response.Status())
+}
@@ -22,7 +22,7 @@ func newMockStore() *mockStore {
 }
 
 func (s *mockStore) Add(key string, data interface{}) int {
-	for _, handler := range data.([]Handler) {
+	for _, handler := range data.(*routeData).handlers {
 		handler(nil)
 	}
 	return s.store.Add(key, data)
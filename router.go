@@ -6,6 +6,7 @@
 package tigo
 
 import (
+	"errors"
 	"net/http"
 	"sort"
 	"strings"
@@ -24,9 +25,13 @@ type (
 		RouteGroup
 		Render              Render
 		OnError             ErrorHandler
+		ErrorTemplate       string // template rendered by DefaultErrorHandler for HTML error responses
 		IgnoreTrailingSlash bool // whether to ignore trailing slashes in the end of the request URL
+		Debug               bool // debug mode, in which JSON and XML responses are indented
+		Upload              UploadConfig // limits of multipart requests, which can be overridden with UploadLimit
 		pool                sync.Pool
 		routes              []*Route
+		groups              []*RouteGroup
 		namedRoutes         map[string]*Route
 		stores              map[string]routeStore
 		maxParams           int
@@ -34,6 +39,12 @@ type (
 		notFoundHandlers    []Handler
 	}
 
+	// routeData is the data kept in a routeStore for a route: the route itself and its combined handlers.
+	routeData struct {
+		route    *Route
+		handlers []Handler
+	}
+
 	// routeStore stores route paths and the corresponding handlers.
 	routeStore interface {
 		Add(key string, data interface{}) int
@@ -77,7 +88,19 @@ func New() *Router {
 func (r *Router) ServeHTTP(res http.ResponseWriter, req *http.Request) {
 	c := r.pool.Get().(*Context)
 	c.init(res, req)
-	c.handlers, c.pnames = r.find(req.Method, r.normalizeRequestPath(req.URL.Path), c.pvalues)
+	path := r.normalizeRequestPath(req.URL.Path)
+	c.route, c.handlers, c.pnames = r.find(req.Method, path, c.pvalues)
+	if c.route != nil {
+		c.group = c.route.group
+	} else {
+		c.group = r.groupOf(path)
+		for g := c.group; g != nil; g = g.parent {
+			if g.notFoundHandlers != nil {
+				c.handlers = g.notFoundHandlers
+				break
+			}
+		}
+	}
 	if err := c.Next(); err != nil {
 		r.handleError(c, err)
 	}
@@ -109,8 +132,31 @@ func (r *Router) NotFound(handlers ...Handler) {
 }
 
 // handleError is the error handler for handling any unhandled errors.
+// The error is handled by the error handlers registered with the route group of the request,
+// or by OnError or DefaultErrorHandler if none matches.
+// The headers of a StatusError are added to the response before the error handler is called.
+// If the response is already committed, e.g. after a partial response, the error can no longer be sent
+// and no error handler is called.
 func (r *Router) handleError(c *Context, err error) {
-	if r.OnError != nil{
+	if c.Written() {
+		return
+	}
+	group := c.group
+	if group == nil {
+		group = &r.RouteGroup
+	}
+	err = group.mapError(err)
+	var statusError *StatusError
+	if errors.As(err, &statusError) {
+		for key, values := range statusError.Headers {
+			for _, value := range values {
+				c.Response.Header().Add(key, value)
+			}
+		}
+	}
+	if handler := group.errorHandler(err); handler != nil {
+		handler(c, err)
+	}else if r.OnError != nil{
 		//if httpError, ok := err.(HTTPError); ok {
 		//	c.Response.WriteHeader(httpError.StatusCode())
 		//}else{
@@ -118,11 +164,7 @@ func (r *Router) handleError(c *Context, err error) {
 		//}
 		r.OnError(c, err)
 	}else{
-		if httpError, ok := err.(HTTPError); ok {
-			http.Error(c.Response, httpError.Error(), httpError.StatusCode())
-		} else {
-			http.Error(c.Response, err.Error(), http.StatusInternalServerError)
-		}
+		DefaultErrorHandler(c, err)
 	}
 }
 
@@ -142,20 +184,21 @@ func (r *Router) addRoute(route *Route, handlers []Handler) {
 		path = path[:len(path) - 1] + "<:.*>"
 	}
 
-	if n := store.Add(path, handlers); n > r.maxParams {
+	if n := store.Add(path, &routeData{route, handlers}); n > r.maxParams {
 		r.maxParams = n
 	}
 }
 
-func (r *Router) find(method, path string, pvalues []string) (handlers []Handler, pnames []string) {
-	var hh interface{}
+func (r *Router) find(method, path string, pvalues []string) (route *Route, handlers []Handler, pnames []string) {
+	var data interface{}
 	if store := r.stores[method]; store != nil {
-		hh, pnames = store.Get(path, pvalues)
+		data, pnames = store.Get(path, pvalues)
 	}
-	if hh != nil {
-		return hh.([]Handler), pnames
+	if data != nil {
+		rd := data.(*routeData)
+		return rd.route, rd.handlers, pnames
 	}
-	return r.notFoundHandlers, pnames
+	return nil, r.notFoundHandlers, pnames
 }
 
 func (r *Router) findAllowedMethods(path string) map[string]bool {
@@ -189,6 +232,7 @@ func NotFoundHandler(*Context) error {
 // MethodNotAllowedHandler handles the situation when a request has matching route without matching HTTP method.
 // In this case, the handler will respond with an Allow HTTP header listing the allowed HTTP methods.
 // Otherwise, the handler will do nothing and let the next handler (usually a NotFoundHandler) to handle the problem.
+// Except for OPTIONS requests, a 405 HTTP error is returned, which is handled like the errors of the route group.
 func MethodNotAllowedHandler(c *Context) error {
 	methods := c.Router().findAllowedMethods(c.Request.URL.Path)
 	if len(methods) == 0 {
@@ -204,7 +248,7 @@ func MethodNotAllowedHandler(c *Context) error {
 	sort.Strings(ms)
 	c.Response.Header().Set("Allow", strings.Join(ms, ", "))
 	if c.Request.Method != "OPTIONS" {
-		c.Response.WriteHeader(http.StatusMethodNotAllowed)
+		return NewHTTPError(http.StatusMethodNotAllowed)
 	}
 	c.Abort()
 	return nil
@@ -74,14 +74,41 @@ func TestRouterAdd(t *testing.T) {
 
 func TestRouterFind(t *testing.T) {
 	r := New()
-	r.add("GET", "/users/<id>", []Handler{NotFoundHandler})
+	route := r.add("GET", "/users/<id>", []Handler{NotFoundHandler})
 	pvalues := make([]string, 10)
-	handlers, pnames := r.find("GET", "/users/1", pvalues)
+	found, handlers, pnames := r.find("GET", "/users/1", pvalues)
+	assert.Equal(t, route, found)
 	assert.Equal(t, 1, len(handlers))
 	if assert.Equal(t, 1, len(pnames)) {
 		assert.Equal(t, "id", pnames[0])
 	}
 	assert.Equal(t, "1", pvalues[0])
+
+	found, handlers, _ = r.find("GET", "/posts", pvalues)
+	assert.Nil(t, found)
+	assert.Equal(t, r.notFoundHandlers, handlers)
+}
+
+func TestRouterContextRoute(t *testing.T) {
+	r := New()
+	var route *Route
+	users := r.GET("/users/<id>", func(c *Context) error {
+		route = c.Route()
+		return nil
+	}).Name("user")
+	r.NotFound(func(c *Context) error {
+		route = c.Route()
+		return nil
+	})
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/users/1", nil)
+	r.ServeHTTP(res, req)
+	assert.Equal(t, users, route)
+
+	req, _ = http.NewRequest("GET", "/posts", nil)
+	r.ServeHTTP(res, req)
+	assert.Nil(t, route)
 }
 
 func TestRouterNormalizeRequestPath(t *testing.T) {
@@ -0,0 +1,168 @@
+package tigo
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CSPNonceKey is the key used to store and retrieve the CSP nonce of the current request in the Context.
+const CSPNonceKey = "CSPNonce"
+
+// CSPNonceSource is a placeholder source in a ContentSecurityPolicy that is replaced by
+// the nonce of the current request, e.g. 'nonce-r4nd0m'.
+const CSPNonceSource = "'nonce'"
+
+// SecureConfig specifies the security headers written by Secure().
+// Headers with empty values are not written.
+type SecureConfig struct {
+	HSTSMaxAge                int    //max-age of Strict-Transport-Security in seconds, 0 disables the header
+	HSTSIncludeSubdomains     bool   //add includeSubDomains to Strict-Transport-Security
+	HSTSPreload               bool   //add preload to Strict-Transport-Security
+	ContentTypeNosniff        bool   //write X-Content-Type-Options: nosniff
+	FrameOptions              string //X-Frame-Options, such as DENY or SAMEORIGIN
+	ReferrerPolicy            string //Referrer-Policy
+	PermissionsPolicy         string //Permissions-Policy
+	CrossOriginOpenerPolicy   string //Cross-Origin-Opener-Policy
+	CrossOriginEmbedderPolicy string //Cross-Origin-Embedder-Policy
+	CrossOriginResourcePolicy string //Cross-Origin-Resource-Policy
+	ContentSecurityPolicy     *ContentSecurityPolicy
+	CSPReportOnly             bool //write Content-Security-Policy-Report-Only instead of Content-Security-Policy
+	TrustForwardedProto       bool //detect HTTPS requests with X-Forwarded-Proto, only enable behind a trusted proxy
+}
+
+// DefaultSecureConfig returns a strict configuration suitable for most HTML applications.
+// A new configuration is returned on each call, so it can be modified freely.
+func DefaultSecureConfig() SecureConfig {
+	return SecureConfig{
+		HSTSMaxAge:                31536000,
+		HSTSIncludeSubdomains:     true,
+		ContentTypeNosniff:        true,
+		FrameOptions:              "DENY",
+		ReferrerPolicy:            "strict-origin-when-cross-origin",
+		CrossOriginOpenerPolicy:   "same-origin",
+		CrossOriginResourcePolicy: "same-origin",
+		ContentSecurityPolicy: NewContentSecurityPolicy().
+			Add("default-src", "'self'").
+			Add("script-src", "'self'", CSPNonceSource).
+			Add("style-src", "'self'", CSPNonceSource).
+			Add("object-src", "'none'").
+			Add("base-uri", "'self'").
+			Add("frame-ancestors", "'none'"),
+	}
+}
+
+// Secure returns a handler that writes security related response headers.
+// If a content security policy is configured, a fresh nonce is generated for every request.
+// The nonce can be read with Context.CSPNonce() and by templates with the "cspNonce" function.
+// Strict-Transport-Security is only written for HTTPS requests. The X-Forwarded-Proto header
+// is ignored unless SecureConfig.TrustForwardedProto is set.
+func Secure(config ...SecureConfig) Handler {
+	var cfg SecureConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	} else {
+		cfg = DefaultSecureConfig()
+	}
+	hsts := ""
+	if cfg.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(cfg.HSTSMaxAge)
+		if cfg.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		if cfg.HSTSPreload {
+			hsts += "; preload"
+		}
+	}
+	cspHeader := "Content-Security-Policy"
+	if cfg.CSPReportOnly {
+		cspHeader = "Content-Security-Policy-Report-Only"
+	}
+	return func(c *Context) error {
+		h := c.Response.Header()
+		if hsts != "" && (c.Request.TLS != nil || cfg.TrustForwardedProto && c.Request.Header.Get("X-Forwarded-Proto") == "https") {
+			h.Set("Strict-Transport-Security", hsts)
+		}
+		if cfg.ContentTypeNosniff {
+			h.Set("X-Content-Type-Options", "nosniff")
+		}
+		setHeaderIfNotEmpty(h, "X-Frame-Options", cfg.FrameOptions)
+		setHeaderIfNotEmpty(h, "Referrer-Policy", cfg.ReferrerPolicy)
+		setHeaderIfNotEmpty(h, "Permissions-Policy", cfg.PermissionsPolicy)
+		setHeaderIfNotEmpty(h, "Cross-Origin-Opener-Policy", cfg.CrossOriginOpenerPolicy)
+		setHeaderIfNotEmpty(h, "Cross-Origin-Embedder-Policy", cfg.CrossOriginEmbedderPolicy)
+		setHeaderIfNotEmpty(h, "Cross-Origin-Resource-Policy", cfg.CrossOriginResourcePolicy)
+		if cfg.ContentSecurityPolicy != nil {
+			nonce, err := newCSPNonce()
+			if err != nil {
+				return err
+			}
+			c.Set(CSPNonceKey, nonce)
+			h.Set(cspHeader, cfg.ContentSecurityPolicy.Build(nonce))
+		}
+		return nil
+	}
+}
+
+// CSPNonce returns the CSP nonce generated by Secure() for the current request.
+// An empty string is returned if no nonce has been generated.
+func (c *Context) CSPNonce() string {
+	nonce, _ := c.Get(CSPNonceKey).(string)
+	return nonce
+}
+
+// ContentSecurityPolicy builds the value of a Content-Security-Policy header.
+type ContentSecurityPolicy struct {
+	directives []string
+	sources    map[string][]string
+}
+
+// NewContentSecurityPolicy creates an empty ContentSecurityPolicy.
+func NewContentSecurityPolicy() *ContentSecurityPolicy {
+	return &ContentSecurityPolicy{sources: make(map[string][]string)}
+}
+
+// Add appends the sources to the given directive. Use CSPNonceSource to allow
+// scripts or styles carrying the nonce of the current request.
+func (p *ContentSecurityPolicy) Add(directive string, sources ...string) *ContentSecurityPolicy {
+	if _, ok := p.sources[directive]; !ok {
+		p.directives = append(p.directives, directive)
+	}
+	p.sources[directive] = append(p.sources[directive], sources...)
+	return p
+}
+
+// Build returns the header value of the policy with CSPNonceSource replaced by the given nonce.
+func (p *ContentSecurityPolicy) Build(nonce string) string {
+	parts := make([]string, 0, len(p.directives))
+	for _, directive := range p.directives {
+		value := directive
+		for _, source := range p.sources[directive] {
+			if source == CSPNonceSource {
+				if nonce == "" {
+					continue
+				}
+				source = "'nonce-" + nonce + "'"
+			}
+			value += " " + source
+		}
+		parts = append(parts, value)
+	}
+	return strings.Join(parts, "; ")
+}
+
+func newCSPNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func setHeaderIfNotEmpty(h http.Header, key, value string) {
+	if value != "" {
+		h.Set(key, value)
+	}
+}
@@ -0,0 +1,88 @@
+package tigo
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecure(t *testing.T) {
+	h := Secure()
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/users/", nil)
+	c := NewContext(res, req)
+	assert.Nil(t, h(c))
+	assert.Equal(t, "nosniff", res.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "DENY", res.Header().Get("X-Frame-Options"))
+	assert.Equal(t, "", res.Header().Get("Strict-Transport-Security"))
+	assert.Equal(t, "", res.Header().Get("Permissions-Policy"))
+	nonce := c.CSPNonce()
+	assert.NotEqual(t, "", nonce)
+	assert.True(t, strings.Contains(res.Header().Get("Content-Security-Policy"), "script-src 'self' 'nonce-"+nonce+"'"))
+
+	req.Header.Set("X-Forwarded-Proto", "https")
+	res = httptest.NewRecorder()
+	c = NewContext(res, req)
+	assert.Nil(t, h(c))
+	assert.Equal(t, "", res.Header().Get("Strict-Transport-Security"))
+	assert.NotEqual(t, nonce, c.CSPNonce())
+
+	config := DefaultSecureConfig()
+	config.TrustForwardedProto = true
+	config.ContentSecurityPolicy.Add("img-src", "data:")
+	res = httptest.NewRecorder()
+	assert.Nil(t, Secure(config)(NewContext(res, req)))
+	assert.Equal(t, "max-age=31536000; includeSubDomains", res.Header().Get("Strict-Transport-Security"))
+	assert.Contains(t, res.Header().Get("Content-Security-Policy"), "img-src data:")
+	assert.NotContains(t, DefaultSecureConfig().ContentSecurityPolicy.Build(""), "img-src")
+
+	req.Header.Del("X-Forwarded-Proto")
+	req.TLS = &tls.ConnectionState{}
+	res = httptest.NewRecorder()
+	assert.Nil(t, h(NewContext(res, req)))
+	assert.Equal(t, "max-age=31536000; includeSubDomains", res.Header().Get("Strict-Transport-Security"))
+
+	h = Secure(SecureConfig{
+		PermissionsPolicy:     "geolocation=()",
+		ContentSecurityPolicy: NewContentSecurityPolicy().Add("default-src", "'self'").Add("default-src", "https://cdn.example.com"),
+		CSPReportOnly:         true,
+	})
+	res = httptest.NewRecorder()
+	c = NewContext(res, req)
+	assert.Nil(t, h(c))
+	assert.Equal(t, "", res.Header().Get("X-Frame-Options"))
+	assert.Equal(t, "geolocation=()", res.Header().Get("Permissions-Policy"))
+	assert.Equal(t, "default-src 'self' https://cdn.example.com", res.Header().Get("Content-Security-Policy-Report-Only"))
+}
+
+func TestContentSecurityPolicy(t *testing.T) {
+	p := NewContentSecurityPolicy().Add("script-src", "'self'", CSPNonceSource).Add("object-src", "'none'")
+	assert.Equal(t, "script-src 'self' 'nonce-abc'; object-src 'none'", p.Build("abc"))
+	assert.Equal(t, "script-src 'self'; object-src 'none'", p.Build(""))
+}
+
+func TestSecureCSPNonceFunc(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "tigo")
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "index.html"), []byte(`<script nonce="{{cspNonce}}"></script>`), 0644)
+
+	router := New()
+	router.Render = NewViewRender(ViewRenderConfig{Root: dir, Extension: ".html"})
+	router.Use(Secure())
+	router.GET("/", func(c *Context) error {
+		return c.RenderFile("index", nil)
+	})
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/", nil)
+	router.ServeHTTP(res, req)
+	nonce := strings.TrimSuffix(strings.TrimPrefix(res.Body.String(), `<script nonce="`), `"></script>`)
+	assert.NotEqual(t, "", nonce)
+	assert.True(t, strings.Contains(res.Header().Get("Content-Security-Policy"), "'nonce-"+nonce+"'"))
+}
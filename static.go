@@ -0,0 +1,335 @@
+package tigo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StaticConfig specifies how RouteGroup.StaticFS and RouteGroup.File serve files.
+type StaticConfig struct {
+	Index         string         //file served for a directory, defaults to "index.html"
+	Browse        bool           //list the directory content if a directory has no index file
+	AllowHidden   bool           //serve files and directories whose names start with "."
+	SPA           bool           //serve the root index file for paths that do not match any file
+	Precompressed bool           //serve "name.br" or "name.gz" sidecar files to clients accepting them
+	CacheRules    []CacheRule    //Cache-Control rules, the first matching rule applies
+	Manifest      *AssetManifest //serve fingerprinted URLs created by the manifest from their original files
+}
+
+// CacheRule sets the Cache-Control header of the files it matches.
+type CacheRule struct {
+	Pattern       string //path.Match pattern on the file base name, such as "*.css"; empty matches all files
+	Fingerprinted bool   //only match file names containing a content hash, such as "app.3f2a9c1d.js"
+	Value         string //Cache-Control header value
+}
+
+// ImmutableCacheRule lets clients cache fingerprinted files for a year without revalidation.
+var ImmutableCacheRule = CacheRule{Fingerprinted: true, Value: "public, max-age=31536000, immutable"}
+
+var fingerprintRegexp = regexp.MustCompile(`[.-][0-9a-f]{8,64}\.[^.]+$`)
+
+// match reports whether the rule applies to the named file.
+func (r CacheRule) match(name string) bool {
+	base := path.Base(name)
+	if r.Fingerprinted && !fingerprintRegexp.MatchString(base) {
+		return false
+	}
+	if r.Pattern == "" {
+		return true
+	}
+	ok, _ := path.Match(r.Pattern, base)
+	return ok
+}
+
+// fileServer serves files from a file system, caching the strong ETags of the files it has served.
+type fileServer struct {
+	fsys   fs.FS
+	config StaticConfig
+	etags  *etagCache
+	root   string //directory of fsys prefixed to the names of the ETag cache when it is shared
+}
+
+// maxETags is the number of ETags cached by a file server.
+const maxETags = 1024
+
+type etagKey struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+// etagCache is a cache of ETags with a bounded number of entries.
+type etagCache struct {
+	mutex sync.RWMutex
+	size  int
+	etags map[etagKey]string
+}
+
+func newFileServer(fsys fs.FS, config StaticConfig) *fileServer {
+	if config.Index == "" {
+		config.Index = "index.html"
+	}
+	return &fileServer{fsys: fsys, config: config, etags: newETagCache(maxETags)}
+}
+
+// staticPath converts the request path into a file name within the static root.
+// It returns false if the path must not be served: it is outside the route prefix,
+// it contains ".." segments, backslashes or encoded separators, or it refers to a hidden file.
+func staticPath(req *http.Request, prefix string, allowHidden bool) (string, bool) {
+	raw := strings.ToLower(req.URL.EscapedPath())
+	if strings.Contains(raw, "%2f") || strings.Contains(raw, "%5c") || strings.Contains(raw, "%00") {
+		return "", false
+	}
+	urlPath := req.URL.Path
+	if !strings.HasPrefix(urlPath, prefix) {
+		return "", false
+	}
+	rest := urlPath[len(prefix):]
+	if strings.ContainsAny(rest, "\\\x00") {
+		return "", false
+	}
+	for _, segment := range strings.Split(rest, "/") {
+		if segment == ".." || !allowHidden && len(segment) > 1 && segment[0] == '.' {
+			return "", false
+		}
+	}
+	name := strings.TrimPrefix(path.Clean("/"+rest), "/")
+	if name == "" {
+		name = "."
+	}
+	return name, fs.ValidPath(name)
+}
+
+// serve serves the named file or directory according to the config.
+func (s *fileServer) serve(c *Context, name string, allowDir bool) error {
+	requested := name
+	if original := s.config.Manifest.original(name); original != "" {
+		name = original
+	}
+	file, err := s.fsys.Open(name)
+	if err != nil {
+		return NewHTTPError(http.StatusNotFound)
+	}
+	defer file.Close()
+	fstat, err := file.Stat()
+	if err != nil {
+		return NewHTTPError(http.StatusNotFound)
+	}
+	if !fstat.IsDir() {
+		return s.serveFile(c, name, requested, file, fstat)
+	}
+	if !allowDir {
+		return NewHTTPError(http.StatusNotFound)
+	}
+
+	indexName := path.Join(name, s.config.Index)
+	index, err := s.fsys.Open(indexName)
+	if err == nil {
+		defer index.Close()
+		if istat, err := index.Stat(); err == nil && !istat.IsDir() {
+			if redirectDir(c) {
+				return nil
+			}
+			return s.serveFile(c, indexName, indexName, index, istat)
+		}
+	}
+	if !s.config.Browse {
+		return NewHTTPError(http.StatusNotFound)
+	}
+	if redirectDir(c) {
+		return nil
+	}
+	dir, ok := file.(fs.ReadDirFile)
+	if !ok {
+		return NewHTTPError(http.StatusNotFound)
+	}
+	entries, err := dir.ReadDir(-1)
+	if err != nil {
+		return NewHTTPError(http.StatusInternalServerError, "Error reading directory")
+	}
+	return writeDirList(c, entries, s.config.AllowHidden)
+}
+
+// redirectDir redirects the request of a directory to the path with a trailing slash, as http.FileServer does,
+// so that the relative URLs of its index file or listing are resolved in the directory. It reports whether it redirected.
+func redirectDir(c *Context) bool {
+	urlPath := c.Request.URL.Path
+	if strings.HasSuffix(urlPath, "/") {
+		return false
+	}
+	target := path.Base(urlPath) + "/"
+	if c.Request.URL.RawQuery != "" {
+		target += "?" + c.Request.URL.RawQuery
+	}
+	http.Redirect(c.Response, c.Request, target, http.StatusMovedPermanently)
+	return true
+}
+
+// serveFile writes the file, or its precompressed sidecar, using http.ServeContent so that
+// conditional and range requests are supported. A strong ETag is derived from the content.
+// The cache rules are matched against the requested name, which may be a fingerprinted name.
+func (s *fileServer) serveFile(c *Context, name, requested string, file fs.File, fstat fs.FileInfo) error {
+	h := c.Response.Header()
+	for _, rule := range s.config.CacheRules {
+		if rule.match(requested) {
+			h.Set("Cache-Control", rule.Value)
+			break
+		}
+	}
+
+	if s.config.Precompressed {
+		h.Add("Vary", "Accept-Encoding")
+		accept := c.Request.Header.Get("Accept-Encoding")
+		for _, enc := range []struct{ name, ext string }{{"br", ".br"}, {"gzip", ".gz"}} {
+			if !acceptsEncoding(accept, enc.name) {
+				continue
+			}
+			sidecar, err := s.fsys.Open(name + enc.ext)
+			if err != nil {
+				continue
+			}
+			defer sidecar.Close()
+			sstat, err := sidecar.Stat()
+			if err != nil || sstat.IsDir() {
+				continue
+			}
+			if ctype := mime.TypeByExtension(path.Ext(name)); ctype != "" {
+				h.Set("Content-Type", ctype)
+			} else {
+				h.Set("Content-Type", "application/octet-stream")
+			}
+			h.Set("Content-Encoding", enc.name)
+			return s.serveContent(c, name+enc.ext, sidecar, sstat)
+		}
+	}
+	return s.serveContent(c, name, file, fstat)
+}
+
+func (s *fileServer) serveContent(c *Context, name string, file fs.File, fstat fs.FileInfo) error {
+	content, ok := file.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return NewHTTPError(http.StatusInternalServerError, "Error reading file")
+		}
+		content = bytes.NewReader(data)
+	}
+	if etag, err := s.etag(name, fstat, content); err == nil {
+		c.Response.Header().Set("ETag", etag)
+	}
+	http.ServeContent(c.Response, c.Request, fstat.Name(), fstat.ModTime(), content)
+	return nil
+}
+
+// etag returns the strong ETag of the content, which is rewound afterwards.
+func (s *fileServer) etag(name string, fstat fs.FileInfo, content io.ReadSeeker) (string, error) {
+	key := etagKey{filepath.Join(s.root, name), fstat.Size(), fstat.ModTime()}
+	if etag, ok := s.etags.get(key); ok {
+		return etag, nil
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	etag := `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+	s.etags.add(key, etag)
+	return etag, nil
+}
+
+func newETagCache(size int) *etagCache {
+	return &etagCache{size: size, etags: make(map[etagKey]string)}
+}
+
+func (c *etagCache) get(key etagKey) (string, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	etag, ok := c.etags[key]
+	return etag, ok
+}
+
+// add adds an ETag to the cache, evicting an arbitrary entry if the cache is full.
+func (c *etagCache) add(key etagKey, etag string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if _, ok := c.etags[key]; !ok && len(c.etags) >= c.size {
+		for k := range c.etags {
+			delete(c.etags, k)
+			break
+		}
+	}
+	c.etags[key] = etag
+}
+
+// acceptsEncoding reports whether the Accept-Encoding header value accepts the given coding.
+func acceptsEncoding(header, coding string) bool {
+	wildcard := false
+	for _, part := range strings.Split(header, ",") {
+		name, q := parseQualityValue(part)
+		if name == coding {
+			return q > 0
+		}
+		if name == "*" {
+			wildcard = q > 0
+		}
+	}
+	return wildcard
+}
+
+// parseQualityValue splits an element of an Accept style header, such as "gzip;q=0.8",
+// into its lowercase value and its quality, which defaults to 1.
+func parseQualityValue(s string) (string, float64) {
+	fields := strings.Split(s, ";")
+	q := 1.0
+	for _, param := range fields[1:] {
+		param = strings.TrimSpace(param)
+		if len(param) > 2 && (param[0] == 'q' || param[0] == 'Q') && param[1] == '=' {
+			if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+				q = v
+			}
+		}
+	}
+	return strings.ToLower(strings.TrimSpace(fields[0])), q
+}
+
+// writeDirList writes an HTML list of the directory entries.
+func writeDirList(c *Context, entries []fs.DirEntry, allowHidden bool) error {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	base := c.Request.URL.Path
+	if !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+	buf := new(bytes.Buffer)
+	buf.WriteString("<!doctype html>\n<meta name=\"viewport\" content=\"width=device-width\">\n<pre>\n")
+	for _, entry := range entries {
+		name := entry.Name()
+		if !allowHidden && strings.HasPrefix(name, ".") {
+			continue
+		}
+		if entry.IsDir() {
+			name += "/"
+		}
+		link := url.URL{Path: base + name}
+		fmt.Fprintf(buf, "<a href=\"%s\">%s</a>\n", link.EscapedPath(), html.EscapeString(name))
+	}
+	buf.WriteString("</pre>\n")
+	return c.HTML(buf.String())
+}
@@ -0,0 +1,158 @@
+package tigo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteGroupStaticFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html":         {Data: []byte("home")},
+		"css/site.css":       {Data: []byte("body{}")},
+		"docs/readme.txt":    {Data: []byte("readme")},
+		"docs/guide/a.html":  {Data: []byte("a")},
+		"docs/guide/b.html":  {Data: []byte("b")},
+		"docs/guide/.hidden": {Data: []byte("hidden")},
+		".env":               {Data: []byte("SECRET=1")},
+	}
+
+	router := New()
+	router.Group("/ui").StaticFS("/assets/*", fsys, StaticConfig{Browse: true})
+	router.StaticFS("/app/*", fsys, StaticConfig{SPA: true})
+
+	tests := []struct {
+		id     string
+		method string
+		url    string
+		status int
+		body   string
+	}{
+		{"file", "GET", "/ui/assets/css/site.css", http.StatusOK, "body{}"},
+		{"root index", "GET", "/ui/assets/", http.StatusOK, "home"},
+		{"missing", "GET", "/ui/assets/css/none.css", http.StatusNotFound, ""},
+		{"hidden file", "GET", "/ui/assets/.env", http.StatusNotFound, ""},
+		{"hidden in dir", "GET", "/ui/assets/docs/guide/.hidden", http.StatusNotFound, ""},
+		{"dot dot", "GET", "/ui/assets/css/../../.env", http.StatusNotFound, ""},
+		{"encoded dot dot", "GET", "/ui/assets/css/%2e%2e/index.html", http.StatusNotFound, ""},
+		{"encoded slash", "GET", "/ui/assets/css%2fsite.css", http.StatusNotFound, ""},
+		{"backslash", "GET", "/ui/assets/css%5csite.css", http.StatusNotFound, ""},
+		{"listing", "GET", "/ui/assets/docs/guide/", http.StatusOK, "<!doctype html>\n<meta name=\"viewport\" content=\"width=device-width\">\n<pre>\n<a href=\"/ui/assets/docs/guide/a.html\">a.html</a>\n<a href=\"/ui/assets/docs/guide/b.html\">b.html</a>\n</pre>\n"},
+		{"post", "POST", "/ui/assets/css/site.css", http.StatusMethodNotAllowed, ""},
+		{"spa file", "GET", "/app/docs/readme.txt", http.StatusOK, "readme"},
+		{"spa fallback", "GET", "/app/users/1", http.StatusOK, "home"},
+		{"spa directory", "GET", "/app/docs", http.StatusOK, "home"},
+		{"spa hidden", "GET", "/app/.env", http.StatusNotFound, ""},
+	}
+	for _, test := range tests {
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest(test.method, test.url, nil)
+		router.ServeHTTP(res, req)
+		assert.Equal(t, test.status, res.Code, test.id)
+		if test.body != "" {
+			assert.Equal(t, test.body, res.Body.String(), test.id)
+		}
+	}
+
+	// directories are redirected to their path with a trailing slash
+	for url, location := range map[string]string{
+		"/ui/assets/docs/guide": "/ui/assets/docs/guide/",
+		"/ui/assets/docs?a":     "/ui/assets/docs/?a",
+	} {
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", url, nil)
+		router.ServeHTTP(res, req)
+		assert.Equal(t, http.StatusMovedPermanently, res.Code, url)
+		assert.Equal(t, location, res.Header().Get("Location"), url)
+	}
+}
+
+func TestRouteGroupStaticFSPrecompressed(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app.js":               {Data: []byte("plain")},
+		"app.js.gz":            {Data: []byte("gzipped")},
+		"app.js.br":            {Data: []byte("brotli")},
+		"site.3f2a9c1d.css":    {Data: []byte("body{}")},
+		"site.3f2a9c1d.css.gz": {Data: []byte("gzipped css")},
+	}
+	router := New()
+	router.StaticFS("/assets/*", fsys, StaticConfig{
+		Precompressed: true,
+		CacheRules: []CacheRule{
+			ImmutableCacheRule,
+			{Pattern: "*.js", Value: "public, max-age=60"},
+		},
+	})
+
+	tests := []struct {
+		id       string
+		url      string
+		encoding string
+		body     string
+		cache    string
+	}{
+		{"plain", "/assets/app.js", "", "plain", "public, max-age=60"},
+		{"gzip", "/assets/app.js", "gzip, deflate", "gzipped", "public, max-age=60"},
+		{"brotli", "/assets/app.js", "gzip, br", "brotli", "public, max-age=60"},
+		{"brotli refused", "/assets/app.js", "gzip, br;q=0", "gzipped", "public, max-age=60"},
+		{"no sidecar", "/assets/site.3f2a9c1d.css", "br", "body{}", "public, max-age=31536000, immutable"},
+	}
+	for _, test := range tests {
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", test.url, nil)
+		req.Header.Set("Accept-Encoding", test.encoding)
+		router.ServeHTTP(res, req)
+		assert.Equal(t, http.StatusOK, res.Code, test.id)
+		assert.Equal(t, test.body, res.Body.String(), test.id)
+		assert.Equal(t, test.cache, res.Header().Get("Cache-Control"), test.id)
+		assert.Equal(t, "Accept-Encoding", res.Header().Get("Vary"), test.id)
+	}
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/assets/app.js", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	router.ServeHTTP(res, req)
+	assert.Equal(t, "gzip", res.Header().Get("Content-Encoding"))
+	assert.Equal(t, "text/javascript; charset=utf-8", res.Header().Get("Content-Type"))
+	gzipETag := res.Header().Get("ETag")
+
+	res = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/assets/app.js", nil)
+	router.ServeHTTP(res, req)
+	etag := res.Header().Get("ETag")
+	assert.Equal(t, 34, len(etag))
+	assert.NotEqual(t, gzipETag, etag)
+
+	res = httptest.NewRecorder()
+	req.Header.Set("If-None-Match", etag)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusNotModified, res.Code)
+}
+
+func TestETagCache(t *testing.T) {
+	cache := newETagCache(2)
+	cache.add(etagKey{name: "a"}, `"a"`)
+	cache.add(etagKey{name: "b"}, `"b"`)
+	cache.add(etagKey{name: "b"}, `"b2"`)
+	assert.Len(t, cache.etags, 2)
+	etag, ok := cache.get(etagKey{name: "b"})
+	assert.True(t, ok)
+	assert.Equal(t, `"b2"`, etag)
+	cache.add(etagKey{name: "c"}, `"c"`)
+	assert.Len(t, cache.etags, 2)
+	_, ok = cache.get(etagKey{name: "c"})
+	assert.True(t, ok)
+}
+
+func TestAcceptsEncoding(t *testing.T) {
+	assert.True(t, acceptsEncoding("gzip, deflate, br", "br"))
+	assert.True(t, acceptsEncoding("GZIP;q=0.5", "gzip"))
+	assert.False(t, acceptsEncoding("gzip;q=0", "gzip"))
+	assert.False(t, acceptsEncoding("deflate", "gzip"))
+	assert.True(t, acceptsEncoding("*", "br"))
+	assert.False(t, acceptsEncoding("*, br;q=0", "br"))
+	assert.False(t, acceptsEncoding("", "gzip"))
+}
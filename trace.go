@@ -0,0 +1,260 @@
+package tigo
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SpanKey is the key used to store and retrieve the server span of the current request in the Context.
+const SpanKey = "Span"
+
+// tracerKey is the key used to store the tracer of the current request in the Context.
+const tracerKey = "Tracer"
+
+// SpanContext identifies a span as defined by the W3C Trace Context specification.
+type SpanContext struct {
+	TraceID    string //32 lowercase hex characters
+	SpanID     string //16 lowercase hex characters
+	Sampled    bool   //whether the trace is sampled
+	TraceState string //vendor specific tracestate header value, propagated as is
+}
+
+// ParseTraceContext reads the traceparent and tracestate headers.
+// The returned SpanContext is invalid if the traceparent header is missing or malformed.
+func ParseTraceContext(header http.Header) SpanContext {
+	parts := strings.Split(strings.TrimSpace(header.Get("traceparent")), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || !isLowerHex(parts[0]) ||
+		(parts[0] == "00" && len(parts) != 4) {
+		return SpanContext{}
+	}
+	sc := SpanContext{TraceID: parts[1], SpanID: parts[2]}
+	if !sc.IsValid() || len(parts[3]) != 2 || !isLowerHex(parts[3]) {
+		return SpanContext{}
+	}
+	flags, err := strconv.ParseUint(parts[3], 16, 8)
+	if err != nil {
+		return SpanContext{}
+	}
+	sc.Sampled = flags&0x01 != 0
+	sc.TraceState = strings.Join(header.Values("tracestate"), ",")
+	return sc
+}
+
+// IsValid reports whether the trace and span IDs are well formed and not all zeros.
+func (sc SpanContext) IsValid() bool {
+	return len(sc.TraceID) == 32 && isLowerHex(sc.TraceID) && strings.Trim(sc.TraceID, "0") != "" &&
+		len(sc.SpanID) == 16 && isLowerHex(sc.SpanID) && strings.Trim(sc.SpanID, "0") != ""
+}
+
+// TraceParent returns the traceparent header value of the span context.
+func (sc SpanContext) TraceParent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID + "-" + sc.SpanID + "-" + flags
+}
+
+// Inject writes the traceparent and tracestate headers so that the trace continues in a downstream request.
+func (sc SpanContext) Inject(header http.Header) {
+	if !sc.IsValid() {
+		return
+	}
+	header.Set("traceparent", sc.TraceParent())
+	if sc.TraceState != "" {
+		header.Set("tracestate", sc.TraceState)
+	} else {
+		header.Del("tracestate")
+	}
+}
+
+// Tracer creates spans.
+type Tracer interface {
+	// StartSpan starts a span as a child of parent. If parent is invalid, a new trace is started.
+	StartSpan(name string, parent SpanContext) Span
+}
+
+// Span represents a unit of work within a trace.
+type Span interface {
+	// SpanContext returns the identity of the span, used to create child spans and to propagate the trace.
+	SpanContext() SpanContext
+	// SetAttribute records an attribute of the span.
+	SetAttribute(key string, value interface{})
+	// End completes the span. It is exported once it ends.
+	End()
+}
+
+// SpanData contains the recorded information of a completed span.
+type SpanData struct {
+	Name       string                 `json:"name"`
+	TraceID    string                 `json:"trace_id"`
+	SpanID     string                 `json:"span_id"`
+	ParentID   string                 `json:"parent_id,omitempty"`
+	Start      time.Time              `json:"start"`
+	End        time.Time              `json:"end"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// SpanExporter sends completed spans to a tracing backend.
+type SpanExporter interface {
+	ExportSpan(data *SpanData)
+}
+
+// NewTracer creates a Tracer that sends sampled spans to the given exporter when they end.
+func NewTracer(exporter SpanExporter) Tracer {
+	return &tracer{exporter: exporter}
+}
+
+type tracer struct {
+	exporter SpanExporter
+}
+
+func (t *tracer) StartSpan(name string, parent SpanContext) Span {
+	sc := SpanContext{
+		TraceID:    parent.TraceID,
+		SpanID:     newRandomHex(8),
+		Sampled:    parent.Sampled,
+		TraceState: parent.TraceState,
+	}
+	parentID := parent.SpanID
+	if !parent.IsValid() {
+		sc = SpanContext{TraceID: newRandomHex(16), SpanID: sc.SpanID, Sampled: true}
+		parentID = ""
+	}
+	return &span{
+		tracer: t,
+		sc:     sc,
+		data: SpanData{
+			Name:     name,
+			TraceID:  sc.TraceID,
+			SpanID:   sc.SpanID,
+			ParentID: parentID,
+			Start:    time.Now(),
+		},
+	}
+}
+
+type span struct {
+	tracer *tracer
+	sc     SpanContext
+	mutex  sync.Mutex
+	data   SpanData
+	ended  bool
+}
+
+func (s *span) SpanContext() SpanContext {
+	return s.sc
+}
+
+func (s *span) SetAttribute(key string, value interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.data.Attributes == nil {
+		s.data.Attributes = make(map[string]interface{})
+	}
+	s.data.Attributes[key] = value
+}
+
+func (s *span) End() {
+	s.mutex.Lock()
+	if s.ended {
+		s.mutex.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mutex.Unlock()
+	if s.sc.Sampled && s.tracer.exporter != nil {
+		s.tracer.exporter.ExportSpan(&data)
+	}
+}
+
+// noopSpan is returned by Context.StartSpan when the request is not traced.
+type noopSpan struct {
+	sc SpanContext
+}
+
+func (s noopSpan) SpanContext() SpanContext                   { return s.sc }
+func (s noopSpan) SetAttribute(key string, value interface{}) {}
+func (s noopSpan) End()                                       {}
+
+// StdoutExporter returns a SpanExporter that writes every span as a JSON line to writer.
+// It is meant for local development. If writer is nil, os.Stdout is used.
+func StdoutExporter(writer io.Writer) SpanExporter {
+	if writer == nil {
+		writer = os.Stdout
+	}
+	return &stdoutExporter{writer: writer}
+}
+
+type stdoutExporter struct {
+	mutex  sync.Mutex
+	writer io.Writer
+}
+
+func (e *stdoutExporter) ExportSpan(data *SpanData) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.writer.Write(append(b, '\n'))
+}
+
+// Trace returns a handler that continues the W3C trace context sent by the client, or starts a new trace.
+// A server span is started for every request and stored in the context, so that handlers can create
+// child spans with Context.StartSpan and propagate the trace with SpanContext.Inject.
+func Trace(t Tracer) Handler {
+	return func(c *Context) error {
+		s := t.StartSpan(c.Request.Method+" "+c.Request.URL.Path, ParseTraceContext(c.Request.Header))
+		s.SetAttribute("http.method", c.Request.Method)
+		s.SetAttribute("http.target", c.Request.URL.RequestURI())
+		if id := c.RequestID(); id != "" {
+			s.SetAttribute("request_id", id)
+		}
+		c.Set(tracerKey, t)
+		c.Set(SpanKey, s)
+		defer s.End()
+
+		err := c.Next()
+		if err != nil {
+			s.SetAttribute("error", err.Error())
+		}
+		return err
+	}
+}
+
+// Span returns the server span of the current request started by the Trace handler.
+// Nil is returned if the request is not traced.
+func (c *Context) Span() Span {
+	s, _ := c.Get(SpanKey).(Span)
+	return s
+}
+
+// StartSpan starts a child span of the server span of the current request.
+// If the request is not traced, a span that records nothing is returned.
+func (c *Context) StartSpan(name string) Span {
+	parent := c.Span()
+	t, _ := c.Get(tracerKey).(Tracer)
+	if parent == nil || t == nil {
+		return noopSpan{}
+	}
+	return t.StartSpan(name, parent.SpanContext())
+}
+
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !(s[i] >= '0' && s[i] <= '9' || s[i] >= 'a' && s[i] <= 'f') {
+			return false
+		}
+	}
+	return true
+}
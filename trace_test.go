@@ -0,0 +1,86 @@
+package tigo
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTraceContext(t *testing.T) {
+	tests := []struct {
+		id          string
+		traceparent string
+		valid       bool
+		sampled     bool
+	}{
+		{"sampled", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true, true},
+		{"not sampled", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", true, false},
+		{"flags 0a", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0a", true, false},
+		{"flags 0b", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0b", true, true},
+		{"invalid flags", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-zz", false, false},
+		{"future version", "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", true, true},
+		{"missing", "", false, false},
+		{"invalid version", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false, false},
+		{"zero trace id", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", false, false},
+		{"uppercase", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", false, false},
+		{"short span id", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902-01", false, false},
+	}
+	for _, test := range tests {
+		header := http.Header{}
+		header.Set("traceparent", test.traceparent)
+		sc := ParseTraceContext(header)
+		assert.Equal(t, test.valid, sc.IsValid(), test.id)
+		assert.Equal(t, test.sampled, sc.Sampled, test.id)
+	}
+
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	header.Add("tracestate", "rojo=00f067aa0ba902b7")
+	header.Add("tracestate", "congo=t61rcWkgMzE")
+	sc := ParseTraceContext(header)
+	assert.Equal(t, "rojo=00f067aa0ba902b7,congo=t61rcWkgMzE", sc.TraceState)
+
+	out := http.Header{}
+	sc.Inject(out)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", out.Get("traceparent"))
+	assert.Equal(t, "rojo=00f067aa0ba902b7,congo=t61rcWkgMzE", out.Get("tracestate"))
+}
+
+func TestTrace(t *testing.T) {
+	var buf bytes.Buffer
+	router := New()
+	router.Use(RequestID(), Trace(NewTracer(StdoutExporter(&buf))))
+	router.GET("/users", func(c *Context) error {
+		s := c.StartSpan("db")
+		assert.Equal(t, c.Span().SpanContext().TraceID, s.SpanContext().TraceID)
+		s.End()
+		return nil
+	})
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/users", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(res, req)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if assert.Equal(t, 2, len(lines)) {
+		var child, server SpanData
+		json.Unmarshal([]byte(lines[0]), &child)
+		json.Unmarshal([]byte(lines[1]), &server)
+		assert.Equal(t, "db", child.Name)
+		assert.Equal(t, server.SpanID, child.ParentID)
+		assert.Equal(t, "GET /users", server.Name)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.TraceID)
+		assert.Equal(t, "00f067aa0ba902b7", server.ParentID)
+		assert.Equal(t, res.Header().Get("X-Request-ID"), server.Attributes["request_id"])
+	}
+
+	c := NewContext(nil, req)
+	assert.Nil(t, c.Span())
+	assert.False(t, c.StartSpan("db").SpanContext().IsValid())
+}
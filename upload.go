@@ -0,0 +1,290 @@
+package tigo
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// UploadConfigKey is the context key of the upload config set by the UploadLimit handler.
+const UploadConfigKey = "UploadConfig"
+
+// UploadConfig specifies the limits of the multipart requests read by Context.MultipartForm, Context.FormFile
+// and Context.MultipartReader. Violations are reported with 413 and 415 HTTP errors.
+type UploadConfig struct {
+	MaxMemory    int64    //bytes of files kept in memory by MultipartForm, the rest is stored in temporary files, default 32 MB
+	MaxFileSize  int64    //maximum size of a file in bytes, 0 for no limit
+	MaxTotalSize int64    //maximum size of the request body in bytes, 0 for no limit
+	AllowedTypes []string //allowed file types, such as "application/pdf" or "image/*", sniffed from the file content; empty allows all
+}
+
+// UploadLimit returns a handler that sets the upload config of the next handlers, instead of Router.Upload.
+func UploadLimit(config UploadConfig) Handler {
+	return func(c *Context) error {
+		c.Set(UploadConfigKey, config)
+		return nil
+	}
+}
+
+// uploadConfig returns the upload config set by UploadLimit, or Router.Upload.
+func (c *Context) uploadConfig() UploadConfig {
+	config, ok := c.Get(UploadConfigKey).(UploadConfig)
+	if !ok && c.router != nil {
+		config = c.router.Upload
+	}
+	if config.MaxMemory <= 0 {
+		config.MaxMemory = 32 << 20
+	}
+	return config
+}
+
+// MultipartForm parses the multipart request body, including the uploaded files, and returns it.
+// The files larger than UploadConfig.MaxMemory are stored in temporary files, which are removed
+// at the end of the request by net/http. The body is parsed once per request.
+// It returns a 415 HTTP error if the request is not multipart or a file has a type not allowed,
+// and a 413 HTTP error if the body or a file is too large.
+func (c *Context) MultipartForm() (*multipart.Form, error) {
+	if err := c.parseForm(); err != nil {
+		return nil, err
+	}
+	if c.Request.MultipartForm == nil {
+		return nil, NewHTTPError(http.StatusUnsupportedMediaType, "Request is not multipart")
+	}
+	return c.Request.MultipartForm, nil
+}
+
+// FormFile returns the first file uploaded with the named field of a multipart request.
+// It returns a 400 HTTP error if there is no such file, or the errors of MultipartForm.
+func (c *Context) FormFile(name string) (*multipart.FileHeader, error) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil, err
+	}
+	if files := form.File[name]; len(files) > 0 {
+		return files[0], nil
+	}
+	return nil, NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Missing file %v", name))
+}
+
+// SaveUploadedFile saves an uploaded file to dst, creating the directory of dst if needed.
+func (c *Context) SaveUploadedFile(file *multipart.FileHeader, dst string) error {
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	if err := os.MkdirAll(filepath.Dir(dst), 0750); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, src); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// parseForm parses the form of the request once, according to the upload config, as Request.ParseMultipartForm does.
+// The errors are kept so that Form and PostForm, which cannot report them, do not parse the body again.
+func (c *Context) parseForm() error {
+	if c.formParsed {
+		return c.formErr
+	}
+	c.formParsed = true
+	config := c.uploadConfig()
+	r := c.Request
+	if config.MaxTotalSize > 0 && r.Body != nil {
+		r.Body = http.MaxBytesReader(c.Response, r.Body, config.MaxTotalSize)
+	}
+	if err := r.ParseForm(); err != nil {
+		c.formErr = uploadError(err)
+		return c.formErr
+	}
+	reader, err := r.MultipartReader()
+	if err != nil {
+		// MultipartReader marks the body as read even if it fails
+		r.MultipartForm = nil
+		if errors.Is(err, http.ErrNotMultipart) {
+			return nil
+		}
+		c.formErr = uploadError(err)
+		return c.formErr
+	}
+	form, err := readMultipartForm(&UploadReader{reader: reader, config: config}, config.MaxMemory)
+	if err != nil {
+		r.MultipartForm = nil
+		c.formErr = err
+		return err
+	}
+	if r.PostForm == nil {
+		r.PostForm = make(url.Values)
+	}
+	for name, values := range form.Value {
+		r.Form[name] = append(r.Form[name], values...)
+		r.PostForm[name] = append(r.PostForm[name], values...)
+	}
+	r.MultipartForm = form
+	return nil
+}
+
+// readMultipartForm reads a multipart form with multipart.Reader.ReadForm, from the parts of the reader,
+// so that the limits of the upload config are checked while the parts are read, before they are stored.
+func readMultipartForm(reader *UploadReader, maxMemory int64) (*multipart.Form, error) {
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	done := make(chan error, 1)
+	go func() {
+		err := copyParts(writer, reader)
+		pw.CloseWithError(err)
+		done <- err
+	}()
+	form, err := multipart.NewReader(pr, writer.Boundary()).ReadForm(maxMemory)
+	// stop copying the parts if ReadForm failed
+	pr.Close()
+	if copyErr := <-done; copyErr != nil && copyErr != io.ErrClosedPipe {
+		if form != nil {
+			form.RemoveAll()
+		}
+		return nil, copyErr
+	}
+	if err != nil {
+		return nil, uploadError(err)
+	}
+	return form, nil
+}
+
+// copyParts copies the parts read by reader to writer.
+func copyParts(writer *multipart.Writer, reader *UploadReader) error {
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return writer.Close()
+		}
+		if err != nil {
+			return err
+		}
+		w, err := writer.CreatePart(part.Header)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(w, part); err != nil {
+			return err
+		}
+	}
+}
+
+// checkUploadType returns a 415 HTTP error if the type sniffed from the head of a file is not allowed.
+func checkUploadType(filename string, head []byte, config UploadConfig) error {
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	for _, allowed := range config.AllowedTypes {
+		if allowed == contentType || strings.HasSuffix(allowed, "/*") && strings.HasPrefix(contentType, allowed[:len(allowed)-1]) {
+			return nil
+		}
+	}
+	return NewHTTPError(http.StatusUnsupportedMediaType, fmt.Sprintf("File %v has the type %v, which is not allowed", filename, contentType))
+}
+
+func fileTooLargeError(filename string, max int64) error {
+	return NewHTTPError(http.StatusRequestEntityTooLarge, fmt.Sprintf("File %v is larger than %v bytes", filename, max))
+}
+
+// uploadError converts the errors of reading a multipart body into HTTP errors.
+func uploadError(err error) error {
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) {
+		return NewStatusError(http.StatusRequestEntityTooLarge, fmt.Sprintf("Request body is larger than %v bytes", maxBytesError.Limit)).WithCause(err)
+	}
+	var httpError HTTPError
+	if errors.As(err, &httpError) || err == io.EOF {
+		return err
+	}
+	return NewStatusError(http.StatusBadRequest, "Malformed multipart body").WithCause(err)
+}
+
+// UploadReader reads the parts of a multipart request body one by one, as they are received,
+// so that large files can be processed without being buffered in memory or in temporary files.
+type UploadReader struct {
+	reader *multipart.Reader
+	config UploadConfig
+}
+
+// UploadPart is a part of a multipart request body read by UploadReader.
+// Reading it returns a 413 HTTP error once more than UploadConfig.MaxFileSize bytes of a file are read.
+type UploadPart struct {
+	*multipart.Part
+	ContentType string //type sniffed from the content of a file part, empty for other fields
+	reader      io.Reader
+	size        int64
+	maxSize     int64
+}
+
+// MultipartReader returns a reader of the parts of the multipart request body, applying the upload config.
+// It returns a 415 HTTP error if the request is not multipart, and a 500 HTTP error if the body has already
+// been read by MultipartForm, FormFile, Form, PostForm, Read or a previous call.
+func (c *Context) MultipartReader() (*UploadReader, error) {
+	r := c.Request
+	if c.formParsed || r.MultipartForm != nil {
+		return nil, NewStatusError(http.StatusInternalServerError).WithInternal("MultipartReader called after the request body was read")
+	}
+	config := c.uploadConfig()
+	if config.MaxTotalSize > 0 && r.Body != nil {
+		r.Body = http.MaxBytesReader(c.Response, r.Body, config.MaxTotalSize)
+	}
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, NewStatusError(http.StatusUnsupportedMediaType, "Request is not multipart").WithCause(err)
+	}
+	c.formParsed = true
+	return &UploadReader{reader: reader, config: config}, nil
+}
+
+// NextPart returns the next part of the body, or io.EOF if there are no more parts.
+// A 415 HTTP error is returned if the type sniffed from the content of a file is not allowed.
+func (r *UploadReader) NextPart() (*UploadPart, error) {
+	part, err := r.reader.NextPart()
+	if err != nil {
+		return nil, uploadError(err)
+	}
+	p := &UploadPart{Part: part, reader: part}
+	if part.FileName() == "" {
+		return p, nil
+	}
+	p.maxSize = r.config.MaxFileSize
+	buffered := bufio.NewReaderSize(part, 512)
+	head, err := buffered.Peek(512)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, uploadError(err)
+	}
+	p.ContentType, _, _ = mime.ParseMediaType(http.DetectContentType(head))
+	if len(r.config.AllowedTypes) > 0 {
+		if err := checkUploadType(part.FileName(), head, r.config); err != nil {
+			return nil, err
+		}
+	}
+	p.reader = buffered
+	return p, nil
+}
+
+// Read reads the content of the part.
+func (p *UploadPart) Read(data []byte) (int, error) {
+	n, err := p.reader.Read(data)
+	p.size += int64(n)
+	if p.maxSize > 0 && p.size > p.maxSize {
+		return n, fileTooLargeError(p.FileName(), p.maxSize)
+	}
+	if err != nil && err != io.EOF {
+		err = uploadError(err)
+	}
+	return n, err
+}
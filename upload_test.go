@@ -0,0 +1,227 @@
+package tigo
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func newUploadRequest(t *testing.T, fields map[string]string, files map[string][]byte) *http.Request {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	for name, value := range fields {
+		writer.WriteField(name, value)
+	}
+	for name, content := range files {
+		part, err := writer.CreateFormFile(name, name+".bin")
+		assert.Nil(t, err)
+		part.Write(content)
+	}
+	writer.Close()
+	req, _ := http.NewRequest("POST", "/upload", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestContextFormFile(t *testing.T) {
+	dir := t.TempDir()
+	router := New()
+	router.POST("/upload", func(c *Context) error {
+		file, err := c.FormFile("avatar")
+		if err != nil {
+			return err
+		}
+		if err := c.SaveUploadedFile(file, filepath.Join(dir, "avatars", file.Filename)); err != nil {
+			return err
+		}
+		form, err := c.MultipartForm()
+		if err != nil {
+			return err
+		}
+		return c.Text(c.Form("name") + ":" + c.PostForm("name") + ":" + form.File["avatar"][0].Filename)
+	})
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, newUploadRequest(t, map[string]string{"name": "tigo"}, map[string][]byte{"avatar": pngHeader}))
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "tigo:tigo:avatar.bin", res.Body.String())
+	saved, err := os.ReadFile(filepath.Join(dir, "avatars", "avatar.bin"))
+	assert.Nil(t, err)
+	assert.Equal(t, pngHeader, saved)
+
+	res = httptest.NewRecorder()
+	router.ServeHTTP(res, newUploadRequest(t, map[string]string{"name": "tigo"}, nil))
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+	assert.Contains(t, res.Body.String(), "Missing file avatar")
+
+	res = httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/upload", strings.NewReader("name=tigo"))
+	req.Header.Set("Content-Type", MIME_FORM)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, res.Code)
+}
+
+func TestUploadLimit(t *testing.T) {
+	router := New()
+	router.Upload = UploadConfig{MaxFileSize: 100, AllowedTypes: []string{"image/*"}}
+	upload := func(c *Context) error {
+		if _, err := c.FormFile("avatar"); err != nil {
+			return err
+		}
+		return c.Text("ok")
+	}
+	router.POST("/upload", upload)
+	router.POST("/small", UploadLimit(UploadConfig{MaxTotalSize: 100}), upload)
+
+	tests := []struct {
+		id      string
+		url     string
+		content []byte
+		status  int
+	}{
+		{"allowed", "/upload", pngHeader, http.StatusOK},
+		{"file too large", "/upload", append(pngHeader, make([]byte, 100)...), http.StatusRequestEntityTooLarge},
+		{"type not allowed", "/upload", []byte("%PDF-1.4"), http.StatusUnsupportedMediaType},
+		{"body too large", "/small", pngHeader, http.StatusRequestEntityTooLarge},
+	}
+	for _, test := range tests {
+		res := httptest.NewRecorder()
+		req := newUploadRequest(t, nil, map[string][]byte{"avatar": test.content})
+		req.URL.Path = test.url
+		router.ServeHTTP(res, req)
+		assert.Equal(t, test.status, res.Code, test.id)
+	}
+}
+
+func TestContextMultipartReader(t *testing.T) {
+	router := New()
+	router.Upload = UploadConfig{MaxFileSize: 100, AllowedTypes: []string{"image/png"}}
+	router.POST("/upload", func(c *Context) error {
+		reader, err := c.MultipartReader()
+		if err != nil {
+			return err
+		}
+		var result []string
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			data, err := io.ReadAll(part)
+			if err != nil {
+				return err
+			}
+			result = append(result, part.FormName()+"="+part.ContentType+":"+strconv.Itoa(len(data)))
+		}
+		return c.Text(strings.Join(result, ","))
+	})
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, newUploadRequest(t, map[string]string{"name": "tigo"}, map[string][]byte{"avatar": append(pngHeader, make([]byte, 64)...)}))
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "name=:4,avatar=image/png:80", res.Body.String())
+
+	res = httptest.NewRecorder()
+	router.ServeHTTP(res, newUploadRequest(t, nil, map[string][]byte{"avatar": append(pngHeader, make([]byte, 1000)...)}))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, res.Code)
+
+	res = httptest.NewRecorder()
+	router.ServeHTTP(res, newUploadRequest(t, nil, map[string][]byte{"avatar": []byte("GIF89a")}))
+	assert.Equal(t, http.StatusUnsupportedMediaType, res.Code)
+
+	res = httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/upload", strings.NewReader("{}"))
+	req.Header.Set("Content-Type", MIME_JSON)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, res.Code)
+
+	// the body cannot be read again after it has been parsed
+	router.POST("/parsed", func(c *Context) error {
+		if _, err := c.MultipartForm(); err != nil {
+			return err
+		}
+		_, err := c.MultipartReader()
+		return err
+	})
+	res = httptest.NewRecorder()
+	req = newUploadRequest(t, map[string]string{"name": "tigo"}, nil)
+	req.URL.Path = "/parsed"
+	router.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusInternalServerError, res.Code)
+}
+
+// countingReader counts the bytes read from a request body.
+type countingReader struct {
+	io.Reader
+	n int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.n += n
+	return n, err
+}
+
+func TestUploadLimitWhileReading(t *testing.T) {
+	router := New()
+	router.Upload = UploadConfig{MaxFileSize: 100, MaxTotalSize: 200}
+	router.POST("/upload", func(c *Context) error {
+		_, err := c.FormFile("avatar")
+		return err
+	})
+	type profile struct {
+		Name string `form:"name"`
+	}
+	router.POST("/profile", func(c *Context) error {
+		var p profile
+		if err := c.Read(&p); err != nil {
+			return err
+		}
+		return c.Text(p.Name)
+	})
+
+	// a file larger than MaxFileSize is rejected before the rest of the body is read
+	router.Upload.MaxTotalSize = 0
+	req := newUploadRequest(t, nil, map[string][]byte{"avatar": make([]byte, 1<<20)})
+	body := &countingReader{Reader: req.Body}
+	req.Body = io.NopCloser(body)
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, res.Code)
+	assert.Less(t, body.n, 64<<10)
+
+	// Read applies the upload config to forms
+	router.Upload.MaxTotalSize = 200
+	res = httptest.NewRecorder()
+	req = newUploadRequest(t, map[string]string{"name": "tigo"}, nil)
+	req.URL.Path = "/profile"
+	router.ServeHTTP(res, req)
+	assert.Equal(t, "tigo", res.Body.String())
+
+	res = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/profile", strings.NewReader("name="+strings.Repeat("x", 300)))
+	req.Header.Set("Content-Type", MIME_FORM)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, res.Code)
+
+	router.Upload.MaxTotalSize = 0
+	res = httptest.NewRecorder()
+	req = newUploadRequest(t, map[string]string{"name": "tigo"}, map[string][]byte{"avatar": make([]byte, 150)})
+	req.URL.Path = "/profile"
+	router.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, res.Code)
+}
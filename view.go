@@ -2,130 +2,553 @@ package tigo
 
 import (
 	"html/template"
+	texttemplate "text/template"
 	"sync"
 	"fmt"
 	"os"
 	"io"
+	"path"
 	"path/filepath"
+	"io/fs"
 	"io/ioutil"
 	"bytes"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
 )
 
 type ViewRender struct {
 	config   ViewRenderConfig
-	tplMap   map[string]*template.Template
+	tplMap   map[viewKey]*viewEntry
 	tplMutex sync.RWMutex
+	sources  map[string]*viewSource //template sources loaded by Init, keyed by template name
+	srcMutex sync.RWMutex
+	stop     chan struct{}
 }
 
 type ViewRenderConfig struct {
+	FileSystem        fs.FS            //template source, such as an embed.FS, Root is a directory within it. Default: disk
 	Root              string           //view root
 	Extension         string           //template extension
 	Master            string           //template master
 	Partials          []string         //template partial, such as head, foot
 	Funcs             template.FuncMap //template functions
+	Assets            *AssetManifest   //fingerprinted asset URLs, resolved by the "asset" template function
 	DisableCache      bool             //disable cache, debug mode
 	DisableFilePartial bool             //enable render file use partial
+	PlainText         bool             //parse the templates with text/template, without HTML escaping, e.g. for plain-text emails
+	Development       bool             //watch template files after Init and re-parse the changed ones
+	WatchInterval     time.Duration    //polling interval of the development watcher, default 1s
+}
+
+// viewKey identifies a cached template: a template name rendered within a layout, or alone if layout is empty.
+type viewKey struct {
+	name   string
+	layout string
+}
+
+// viewEntry is a parsed template with its layouts and partials. The parsed template itself is never executed:
+// renders execute clones taken from a pool, so that request-scoped functions are bound to a clone
+// used by a single render at a time instead of mutating the shared template.
+type viewEntry struct {
+	tpl   viewTemplate
+	root  string           //name of the executed template, the outermost layout or the template itself
+	files []string         //names of the templates parsed into tpl
+	stubs template.FuncMap //functions used when parsing, restored after a render to release request data
+	pool  sync.Pool
+}
+
+// extendsRegexp matches the directive declaring the parent of a layout, such as {{/* extends "layout/base" */}},
+// which must be the first action of the layout file.
+var extendsRegexp = regexp.MustCompile(`^\s*\{\{-?\s*/\*\s*extends\s+"([^"]+)"\s*\*/\s*-?\}\}`)
+
+// execute renders the named template of a clone of the entry bound to the given functions.
+func (e *viewEntry) execute(out io.Writer, name string, data interface{}, funcs template.FuncMap) error {
+	tpl, _ := e.pool.Get().(viewTemplate)
+	if tpl == nil {
+		var err error
+		if tpl, err = e.tpl.Clone(); err != nil {
+			return err
+		}
+	}
+	defer func() {
+		tpl.Funcs(e.stubs)
+		e.pool.Put(tpl)
+	}()
+	tpl.Funcs(funcs)
+	return tpl.ExecuteTemplate(out, name, data)
+}
+
+// viewSource is the content of a template file and its modification time when it was read.
+type viewSource struct {
+	file    string
+	content []byte
+	modTime time.Time
 }
 
 func NewViewRender(config ViewRenderConfig) *ViewRender {
 	return &ViewRender{
 		config: config,
-		tplMap: make(map[string]*template.Template),
+		tplMap: make(map[viewKey]*viewEntry),
 		tplMutex: sync.RWMutex{},
 	}
 }
 
+// Init loads every template under Root, parses and validates all of them, and precompiles
+// the templates of the pages so that syntax errors are reported before the first request.
+// All errors found are returned together. A missing Root is not an error.
+// In development mode, a watcher is started that polls the template files and re-parses
+// the templates depending on the changed files. It is stopped by Close.
 func (r *ViewRender) Init() error {
-	//if r.config.Root != "" {
-	//	if _, err := os.Stat(r.config.Root); os.IsNotExist(err) {
-	//		return fmt.Errorf("ViewRender view root: %v not exists!", r.config.Root)
-	//	}
-	//}
+	sources, err := r.loadSources()
+	if err != nil {
+		return err
+	}
+	r.srcMutex.Lock()
+	r.sources = sources
+	r.srcMutex.Unlock()
+
+	var errs []error
+	for name, src := range sources {
+		tpl := r.newTemplate(name)
+		tpl.Funcs(r.funcs(nil, contextFuncs(nil)))
+		if err := tpl.Parse(string(src.content)); err != nil {
+			errs = append(errs, fmt.Errorf("TemplateEngine render parser name:%v, path:%v, error: %v", name, src.file, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	for name := range sources {
+		if r.isLayout(name, sources) {
+			continue
+		}
+		if _, err := r.template(name, r.config.Master); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	if r.config.Development && r.stop == nil {
+		r.stop = make(chan struct{})
+		go r.watch(r.stop)
+	}
 	return nil
 }
 
+// Close stops the development watcher started by Init.
+func (r *ViewRender) Close() {
+	if r.stop != nil {
+		close(r.stop)
+		r.stop = nil
+	}
+}
+
 
 // Render a template to the screen
 func (r *ViewRender) RenderFile(out io.Writer, name string, data interface{}) error {
-	return r.execute(out, name, data, false)
+	return r.execute(out, name, "", data, contextFuncs(nil))
 }
 
 // Render a template to the screen
 func (r *ViewRender) Render(out io.Writer, name string, data interface{}) error {
-	return r.execute(out, name, data, true)
+	return r.execute(out, name, r.config.Master, data, contextFuncs(nil))
+}
+
+// RenderFuncs render a template with request-bound functions, such as cspNonce
+func (r *ViewRender) RenderFuncs(out io.Writer, name string, data interface{}, useMaster bool, funcs template.FuncMap) error {
+	layout := ""
+	if useMaster {
+		layout = r.config.Master
+	}
+	return r.execute(out, name, layout, data, funcs)
 }
 
-func (r *ViewRender) execute(out io.Writer, name string, data interface{}, useMaster bool) error {
-	var tpl *template.Template
-	var err error
-	var ok bool
+// RenderLayout render a template within the given layout instead of the master, or alone if layout is empty.
+func (r *ViewRender) RenderLayout(out io.Writer, name string, layout string, data interface{}, funcs template.FuncMap) error {
+	return r.execute(out, name, layout, data, funcs)
+}
 
+// RenderBlock render a single block, such as {{define "content"}}, without the rest of the layout.
+// The block is looked up in the template set of the template within layout, or within the master if layout
+// is empty, as rendered by Render, so that the blocks defined only by the layouts can be rendered too.
+func (r *ViewRender) RenderBlock(out io.Writer, name string, layout string, block string, data interface{}, funcs template.FuncMap) error {
+	if layout == "" {
+		layout = r.config.Master
+	}
+	entry, err := r.template(name, layout)
+	if err != nil {
+		return err
+	}
+	if !entry.tpl.Lookup(block) {
+		return fmt.Errorf("ViewRender template %v does not define block %v", name, block)
+	}
+	err = entry.execute(out, block, data, r.funcs(data, funcs))
+	if err != nil {
+		return fmt.Errorf("ViewRender execute template error: %v", err)
+	}
+	return nil
+}
+
+func (r *ViewRender) execute(out io.Writer, name string, layout string, data interface{}, funcs template.FuncMap) error {
+	entry, err := r.template(name, layout)
+	if err != nil {
+		return err
+	}
+
+	// Display the content to the screen
+	err = entry.execute(out, entry.root, data, r.funcs(data, funcs))
+	if err != nil {
+		return fmt.Errorf("ViewRender execute template error: %v", err)
+	}
+
+	return nil
+}
+
+// funcs returns all template functions available to the templates rendered with data.
+func (r *ViewRender) funcs(data interface{}, funcs template.FuncMap) template.FuncMap {
 	allFuncs := make(template.FuncMap, 0)
-	allFuncs["include"] = func(layout string) (template.HTML, error) {
+	allFuncs["include"] = func(layout string, includeData ...interface{}) (template.HTML, error) {
 		buf := new(bytes.Buffer)
-		err := r.execute(buf, layout, data, false)
+		var err error
+		if len(includeData) > 0 {
+			err = r.execute(buf, layout, "", includeData[0], funcs)
+		} else {
+			err = r.execute(buf, layout, "", data, funcs)
+		}
 		return template.HTML(buf.String()), err
 	}
+	allFuncs["partial"] = func(name string, partialData interface{}) (template.HTML, error) {
+		buf := new(bytes.Buffer)
+		err := r.execute(buf, name, "", partialData, funcs)
+		return template.HTML(buf.String()), err
+	}
+	allFuncs["asset"] = r.config.Assets.URL
+	funcsMutex.RLock()
+	for k, v := range templateFuncs {
+		allFuncs[k] = v
+	}
+	funcsMutex.RUnlock()
 
 	// Get the plugin collection
 	for k, v := range r.config.Funcs {
 		allFuncs[k] = v
 	}
+	for k, v := range funcs {
+		allFuncs[k] = v
+	}
+	return allFuncs
+}
 
+// template returns the cached template of name within layout, parsing it with the partials if needed.
+// The layouts extended by layout are parsed first, so that the blocks they define are overridden
+// by the layouts extending them and by the template itself.
+func (r *ViewRender) template(name string, layout string) (*viewEntry, error) {
+	key := viewKey{name, layout}
 	r.tplMutex.RLock()
-	tpl, ok = r.tplMap[name]
+	entry, ok := r.tplMap[key]
 	r.tplMutex.RUnlock()
+	if ok && !r.config.DisableCache {
+		return entry, nil
+	}
 
-	exeName := name
-	if useMaster && r.config.Master != "" {
-		exeName = r.config.Master
+	tplList, err := r.layouts(layout)
+	if err != nil {
+		return nil, err
+	}
+	root := name
+	if len(tplList) > 0 {
+		root = tplList[0]
 	}
+	tplList = append(tplList, name)
+	tplList = append(tplList, r.config.Partials...)
 
-	if !ok || r.config.DisableCache {
+	// Loop through each template and test the full path
+	stubs := r.funcs(nil, contextFuncs(nil))
+	tpl := r.newTemplate(name)
+	tpl.Funcs(stubs)
+	for _, v := range tplList {
+		file, content, err := r.source(v)
+		if err != nil {
+			return nil, fmt.Errorf("TemplateEngine render read name:%v, path:%v, error: %v", v, file, err)
+		}
+		tmpl := tpl
+		if v != name {
+			tmpl = tpl.New(v)
+		}
+		err = tmpl.Parse(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("TemplateEngine render parser name:%v, path:%v, error: %v", v, file, err)
+		}
+	}
+	entry = &viewEntry{tpl: tpl, root: root, files: tplList, stubs: stubs}
+	r.tplMutex.Lock()
+	r.tplMap[key] = entry
+	r.tplMutex.Unlock()
+	return entry, nil
+}
+
+// ContentType returns the content type of the rendered templates, or an empty string for HTML.
+func (r *ViewRender) ContentType(name string) string {
+	if r.config.PlainText {
+		return "text/plain; charset=utf-8"
+	}
+	return ""
+}
 
-		tplList := make([]string, 0)
-		if useMaster {
-			//render()
-			if r.config.Master != "" {
-				tplList = append(tplList, r.config.Master)
+// newTemplate creates an empty template set, with text/template if PlainText is set.
+func (r *ViewRender) newTemplate(name string) viewTemplate {
+	if r.config.PlainText {
+		return textTemplate{texttemplate.New(name)}
+	}
+	return htmlTemplate{template.New(name)}
+}
+
+// layouts returns the chain of layouts extended by layout, from the outermost one to layout itself.
+func (r *ViewRender) layouts(layout string) ([]string, error) {
+	var chain []string
+	for layout != "" {
+		for _, v := range chain {
+			if v == layout {
+				return nil, fmt.Errorf("TemplateEngine layout %v extends itself", layout)
 			}
 		}
-		tplList = append(tplList, name)
-		tplList = append(tplList, r.config.Partials...)
+		chain = append([]string{layout}, chain...)
+		file, content, err := r.source(layout)
+		if err != nil {
+			return nil, fmt.Errorf("TemplateEngine render read name:%v, path:%v, error: %v", layout, file, err)
+		}
+		layout = extendsOf(content)
+	}
+	return chain, nil
+}
 
-		// Loop through each template and test the full path
-		tpl = template.New(name).Funcs(allFuncs)
-		for _, v := range tplList {
-			// Get the absolute path of the root template
-			path, err := filepath.Abs(r.config.Root + string(os.PathSeparator) + v + r.config.Extension)
-			if err != nil {
-				return fmt.Errorf("TemplateEngine path:%v error: %v", path, err)
-			}
-			data, err := ioutil.ReadFile(path)
-			if err != nil {
-				return fmt.Errorf("TemplateEngine render read name:%v, path:%v, error: %v", v, path, err)
-			}
-			var tmpl *template.Template
-			if v == name {
-				tmpl = tpl
-			} else {
-				tmpl = tpl.New(v)
-			}
-			_, err = tmpl.Parse(string(data))
-			if err != nil {
-				return fmt.Errorf("TemplateEngine render parser name:%v, path:%v, error: %v", v, path, err)
-			}
+// extendsOf returns the name of the layout extended by the template content, or an empty string.
+func extendsOf(content []byte) string {
+	if m := extendsRegexp.FindSubmatch(content); m != nil {
+		return string(m[1])
+	}
+	return ""
+}
+
+// isLayout reports whether name is the master, one of the partials, or a layout extended by one of the sources,
+// which are not rendered on their own.
+func (r *ViewRender) isLayout(name string, sources map[string]*viewSource) bool {
+	if name == r.config.Master {
+		return true
+	}
+	for _, partial := range r.config.Partials {
+		if name == partial {
+			return true
 		}
-		r.tplMutex.Lock()
-		r.tplMap[name] = tpl
-		r.tplMutex.Unlock()
 	}
+	for _, src := range sources {
+		if extendsOf(src.content) == name {
+			return true
+		}
+	}
+	return false
+}
 
-	// Display the content to the screen
-	err = tpl.Funcs(allFuncs).ExecuteTemplate(out, exeName, data)
+// source returns the content of the named template, from the sources loaded by Init if available.
+func (r *ViewRender) source(name string) (string, []byte, error) {
+	r.srcMutex.RLock()
+	src, ok := r.sources[name]
+	r.srcMutex.RUnlock()
+	if ok && !r.config.DisableCache {
+		return src.file, src.content, nil
+	}
+	return r.readFile(name)
+}
+
+// readFile reads the named template from the configured file system, or from disk by default.
+func (r *ViewRender) readFile(name string) (string, []byte, error) {
+	if r.config.FileSystem != nil {
+		file := path.Join(r.config.Root, name+r.config.Extension)
+		data, err := fs.ReadFile(r.config.FileSystem, file)
+		return file, data, err
+	}
+	// Get the absolute path of the root template
+	file, err := filepath.Abs(r.config.Root + string(os.PathSeparator) + name + r.config.Extension)
 	if err != nil {
-		return fmt.Errorf("ViewRender execute template error: %v", err)
+		return file, nil, err
 	}
+	data, err := ioutil.ReadFile(file)
+	return file, data, err
+}
 
-	return nil
-}
\ No newline at end of file
+// rootFS returns the file system and the directory within it containing the templates.
+func (r *ViewRender) rootFS() (fs.FS, string) {
+	if r.config.FileSystem != nil {
+		root := path.Clean(r.config.Root)
+		if root == "/" {
+			root = "."
+		}
+		return r.config.FileSystem, strings.TrimPrefix(root, "/")
+	}
+	root := r.config.Root
+	if root == "" {
+		root = "."
+	}
+	return os.DirFS(root), "."
+}
+
+// loadSources reads all template files under Root, keyed by template name.
+func (r *ViewRender) loadSources() (map[string]*viewSource, error) {
+	fsys, root := r.rootFS()
+	sources := make(map[string]*viewSource)
+	if _, err := fs.Stat(fsys, root); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return sources, nil
+		}
+		return nil, err
+	}
+	err := fs.WalkDir(fsys, root, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(file, r.config.Extension) {
+			return err
+		}
+		name := strings.TrimSuffix(file, r.config.Extension)
+		if root != "." {
+			name = strings.TrimPrefix(name, root+"/")
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		abs, content, err := r.readFile(name)
+		if err != nil {
+			return err
+		}
+		sources[name] = &viewSource{file: abs, content: content, modTime: info.ModTime()}
+		return nil
+	})
+	return sources, err
+}
+
+// watch polls the template files until stop is closed, and reloads the changed ones.
+func (r *ViewRender) watch(stop chan struct{}) {
+	interval := r.config.WatchInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			r.reload()
+		}
+	}
+}
+
+// reload re-reads the template files that were added, changed or removed since they were loaded,
+// and drops the cached templates depending on them so that they are re-parsed on next use.
+func (r *ViewRender) reload() {
+	fsys, root := r.rootFS()
+	changed := make(map[string]bool)
+	seen := make(map[string]bool)
+	fs.WalkDir(fsys, root, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(file, r.config.Extension) {
+			return nil
+		}
+		name := strings.TrimSuffix(file, r.config.Extension)
+		if root != "." {
+			name = strings.TrimPrefix(name, root+"/")
+		}
+		seen[name] = true
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		r.srcMutex.RLock()
+		src, ok := r.sources[name]
+		r.srcMutex.RUnlock()
+		if ok && src.modTime.Equal(info.ModTime()) {
+			return nil
+		}
+		abs, content, err := r.readFile(name)
+		if err != nil {
+			return nil
+		}
+		r.srcMutex.Lock()
+		r.sources[name] = &viewSource{file: abs, content: content, modTime: info.ModTime()}
+		r.srcMutex.Unlock()
+		changed[name] = true
+		return nil
+	})
+	r.srcMutex.Lock()
+	for name := range r.sources {
+		if !seen[name] {
+			delete(r.sources, name)
+			changed[name] = true
+		}
+	}
+	r.srcMutex.Unlock()
+
+	if len(changed) == 0 {
+		return
+	}
+	r.tplMutex.Lock()
+	defer r.tplMutex.Unlock()
+	for key, entry := range r.tplMap {
+		for _, file := range entry.files {
+			if changed[file] {
+				delete(r.tplMap, key)
+				break
+			}
+		}
+	}
+}
+
+// viewTemplate is a set of html/template or text/template templates.
+type viewTemplate interface {
+	New(name string) viewTemplate
+	Parse(text string) error
+	Funcs(funcs template.FuncMap)
+	Clone() (viewTemplate, error)
+	Lookup(name string) bool
+	ExecuteTemplate(out io.Writer, name string, data interface{}) error
+}
+
+type htmlTemplate struct {
+	t *template.Template
+}
+
+func (h htmlTemplate) New(name string) viewTemplate { return htmlTemplate{h.t.New(name)} }
+func (h htmlTemplate) Parse(text string) error {
+	_, err := h.t.Parse(text)
+	return err
+}
+func (h htmlTemplate) Funcs(funcs template.FuncMap) { h.t.Funcs(funcs) }
+func (h htmlTemplate) Clone() (viewTemplate, error) {
+	t, err := h.t.Clone()
+	return htmlTemplate{t}, err
+}
+func (h htmlTemplate) Lookup(name string) bool { return h.t.Lookup(name) != nil }
+func (h htmlTemplate) ExecuteTemplate(out io.Writer, name string, data interface{}) error {
+	return h.t.ExecuteTemplate(out, name, data)
+}
+
+type textTemplate struct {
+	t *texttemplate.Template
+}
+
+func (h textTemplate) New(name string) viewTemplate { return textTemplate{h.t.New(name)} }
+func (h textTemplate) Parse(text string) error {
+	_, err := h.t.Parse(text)
+	return err
+}
+func (h textTemplate) Funcs(funcs template.FuncMap) { h.t.Funcs(funcs) }
+func (h textTemplate) Clone() (viewTemplate, error) {
+	t, err := h.t.Clone()
+	return textTemplate{t}, err
+}
+func (h textTemplate) Lookup(name string) bool { return h.t.Lookup(name) != nil }
+func (h textTemplate) ExecuteTemplate(out io.Writer, name string, data interface{}) error {
+	return h.t.ExecuteTemplate(out, name, data)
+}
@@ -0,0 +1,243 @@
+package tigo
+
+import (
+	"bytes"
+	"html/template"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestViewRenderFileSystem(t *testing.T) {
+	fsys := fstest.MapFS{
+		"views/layout/master.html": {Data: []byte(`<html>{{include "layout/head"}}{{template "content" .}}</html>`)},
+		"views/layout/head.html":   {Data: []byte(`<title>{{.title}}</title>`)},
+		"views/index.html":         {Data: []byte(`{{define "content"}}<p>{{.title}}</p>{{end}}`)},
+		"views/page.html":          {Data: []byte(`<p>page</p>`)},
+	}
+	render := NewViewRender(ViewRenderConfig{
+		FileSystem: fsys,
+		Root:       "views",
+		Extension:  ".html",
+		Master:     "layout/master",
+	})
+
+	var buf bytes.Buffer
+	assert.Nil(t, render.Render(&buf, "index", M{"title": "Tigo"}))
+	assert.Equal(t, `<html><title>Tigo</title><p>Tigo</p></html>`, buf.String())
+
+	buf.Reset()
+	assert.Nil(t, render.RenderFile(&buf, "page", nil))
+	assert.Equal(t, `<p>page</p>`, buf.String())
+
+	buf.Reset()
+	assert.NotNil(t, render.RenderFile(&buf, "missing", nil))
+}
+
+func TestViewRenderInit(t *testing.T) {
+	fsys := fstest.MapFS{
+		"master.html":  {Data: []byte(`<html>{{template "content" .}}</html>`)},
+		"index.html":   {Data: []byte(`{{define "content"}}index{{end}}`)},
+		"broken1.html": {Data: []byte(`{{if}}`)},
+		"broken2.html": {Data: []byte(`{{unknownFunc}}`)},
+	}
+	render := NewViewRender(ViewRenderConfig{FileSystem: fsys, Extension: ".html", Master: "master"})
+	err := render.Init()
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "broken1")
+		assert.Contains(t, err.Error(), "broken2")
+		assert.NotContains(t, err.Error(), "index")
+	}
+
+	delete(fsys, "broken1.html")
+	delete(fsys, "broken2.html")
+	render = NewViewRender(ViewRenderConfig{FileSystem: fsys, Extension: ".html", Master: "master"})
+	assert.Nil(t, render.Init())
+	assert.NotNil(t, render.tplMap[viewKey{"index", "master"}])
+	assert.Nil(t, render.tplMap[viewKey{"master", "master"}])
+
+	// templates are served from the sources loaded by Init
+	fsys["index.html"] = &fstest.MapFile{Data: []byte(`{{define "content"}}changed{{end}}`)}
+	var buf bytes.Buffer
+	assert.Nil(t, render.Render(&buf, "index", nil))
+	assert.Equal(t, "<html>index</html>", buf.String())
+
+	render = NewViewRender(ViewRenderConfig{FileSystem: fstest.MapFS{}, Root: "views", Extension: ".html"})
+	assert.Nil(t, render.Init())
+}
+
+func TestViewRenderReload(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "tigo")
+	defer os.RemoveAll(dir)
+	write := func(name, content string, modTime time.Time) {
+		file := filepath.Join(dir, name+".html")
+		ioutil.WriteFile(file, []byte(content), 0644)
+		os.Chtimes(file, modTime, modTime)
+	}
+	t0 := time.Now().Add(-time.Hour)
+	write("master", `<html>{{template "content" .}}</html>`, t0)
+	write("index", `{{define "content"}}index{{end}}`, t0)
+	write("about", `{{define "content"}}about{{end}}`, t0)
+
+	render := NewViewRender(ViewRenderConfig{Root: dir, Extension: ".html", Master: "master", Development: true, WatchInterval: time.Hour})
+	assert.Nil(t, render.Init())
+	defer render.Close()
+	about := render.tplMap[viewKey{"about", "master"}]
+
+	write("index", `{{define "content"}}new index{{end}}`, t0.Add(time.Minute))
+	render.reload()
+	assert.Nil(t, render.tplMap[viewKey{"index", "master"}])
+	assert.Equal(t, about, render.tplMap[viewKey{"about", "master"}])
+	var buf bytes.Buffer
+	assert.Nil(t, render.Render(&buf, "index", nil))
+	assert.Equal(t, "<html>new index</html>", buf.String())
+
+	write("master", `<body>{{template "content" .}}</body>`, t0.Add(time.Minute))
+	render.reload()
+	assert.Equal(t, 0, len(render.tplMap))
+	buf.Reset()
+	assert.Nil(t, render.Render(&buf, "about", nil))
+	assert.Equal(t, "<body>about</body>", buf.String())
+}
+
+func TestViewRenderLayoutCache(t *testing.T) {
+	fsys := fstest.MapFS{
+		"master.html": {Data: []byte(`<html>{{template "content" .}}</html>`)},
+		"page.html":   {Data: []byte(`{{define "content"}}page{{end}}page file`)},
+	}
+	render := NewViewRender(ViewRenderConfig{FileSystem: fsys, Extension: ".html", Master: "master"})
+	var buf bytes.Buffer
+	assert.Nil(t, render.RenderFile(&buf, "page", nil))
+	assert.Equal(t, "page file", buf.String())
+	buf.Reset()
+	assert.Nil(t, render.Render(&buf, "page", nil))
+	assert.Equal(t, "<html>page</html>", buf.String())
+	buf.Reset()
+	assert.Nil(t, render.RenderFile(&buf, "page", nil))
+	assert.Equal(t, "page file", buf.String())
+}
+
+func TestViewRenderConcurrent(t *testing.T) {
+	fsys := fstest.MapFS{
+		"master.html": {Data: []byte(`<html>{{template "content" .}}</html>`)},
+		"page.html":   {Data: []byte(`{{define "content"}}{{value}}:{{.}}{{include "part"}}{{end}}{{value}}:{{.}}`)},
+		"part.html":   {Data: []byte(`/{{value}}`)},
+	}
+	render := NewViewRender(ViewRenderConfig{
+		FileSystem: fsys,
+		Extension:  ".html",
+		Master:     "master",
+		Funcs:      template.FuncMap{"value": func() string { return "" }},
+	})
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			value := strconv.Itoa(i)
+			funcs := template.FuncMap{"value": func() string { return value }}
+			var buf bytes.Buffer
+			if i%2 == 0 {
+				assert.Nil(t, render.RenderFuncs(&buf, "page", i, true, funcs))
+				assert.Equal(t, "<html>"+value+":"+value+"/"+value+"</html>", buf.String())
+			} else {
+				assert.Nil(t, render.RenderFuncs(&buf, "page", i, false, funcs))
+				assert.Equal(t, value+":"+value, buf.String())
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestViewRenderNestedLayouts(t *testing.T) {
+	fsys := fstest.MapFS{
+		"base.html":         {Data: []byte(`<title>{{block "title" .}}Site{{end}}</title><nav>{{block "nav" .}}public{{end}}</nav>{{template "content" .}}`)},
+		"master.html":       {Data: []byte(`{{/* extends "base" */}}`)},
+		"admin/master.html": {Data: []byte(`{{/* extends "base" */}}{{define "nav"}}admin{{end}}{{define "title"}}Admin{{end}}`)},
+		"admin/users.html":  {Data: []byte(`{{/* extends "admin/master" */}}{{define "nav"}}admin/users{{end}}`)},
+		"index.html":        {Data: []byte(`{{define "content"}}index{{end}}`)},
+		"users.html":        {Data: []byte(`{{define "title"}}Users{{end}}{{define "content"}}users{{end}}`)},
+		"loop.html":         {Data: []byte(`{{/* extends "loop" */}}`)},
+	}
+	render := NewViewRender(ViewRenderConfig{FileSystem: fsys, Extension: ".html", Master: "master"})
+	assert.Nil(t, render.Init())
+	assert.Nil(t, render.tplMap[viewKey{"base", "master"}])
+	assert.Nil(t, render.tplMap[viewKey{"admin/master", "master"}])
+
+	tests := []struct {
+		name, layout, expected string
+	}{
+		{"index", "master", `<title>Site</title><nav>public</nav>index`},
+		{"users", "master", `<title>Users</title><nav>public</nav>users`},
+		{"index", "admin/master", `<title>Admin</title><nav>admin</nav>index`},
+		{"users", "admin/master", `<title>Users</title><nav>admin</nav>users`},
+		{"users", "admin/users", `<title>Users</title><nav>admin/users</nav>users`},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		assert.Nil(t, render.RenderLayout(&buf, test.name, test.layout, nil, nil), test.name+" in "+test.layout)
+		assert.Equal(t, test.expected, buf.String(), test.name+" in "+test.layout)
+	}
+
+	var buf bytes.Buffer
+	assert.NotNil(t, render.RenderLayout(&buf, "index", "loop", nil, nil))
+	assert.NotNil(t, render.RenderLayout(&buf, "index", "missing", nil, nil))
+}
+
+func TestViewRenderBlock(t *testing.T) {
+	router := New()
+	router.Render = NewViewRender(ViewRenderConfig{
+		FileSystem: fstest.MapFS{
+			"master.html": {Data: []byte(`<html>{{block "nav" .}}<nav>{{len .}}</nav>{{end}}{{template "content" .}}</html>`)},
+			"users.html":  {Data: []byte(`{{define "content"}}<ul>{{range .}}{{partial "user" .}}{{end}}</ul>{{end}}`)},
+			"user.html":   {Data: []byte(`<li>{{.}}</li>`)},
+		},
+		Extension: ".html",
+		Master:    "master",
+	})
+	users := []string{"a", "b"}
+	router.GET("/users", func(c *Context) error { return c.RenderFragment("users", "content", users) })
+	router.GET("/missing", func(c *Context) error { return c.RenderBlock("users", "missing", users) })
+	router.GET("/nav", func(c *Context) error { return c.RenderBlock("users", "nav", users) })
+
+	tests := []struct {
+		id      string
+		url     string
+		headers map[string]string
+		body    string
+	}{
+		{"page", "/users", nil, "<html><nav>2</nav><ul><li>a</li><li>b</li></ul></html>"},
+		{"htmx", "/users", map[string]string{"HX-Request": "true"}, "<ul><li>a</li><li>b</li></ul>"},
+		{"boosted", "/users", map[string]string{"HX-Request": "true", "HX-Boosted": "true"}, "<html><nav>2</nav><ul><li>a</li><li>b</li></ul></html>"},
+	}
+	for _, test := range tests {
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", test.url, nil)
+		for k, v := range test.headers {
+			req.Header.Set(k, v)
+		}
+		router.ServeHTTP(res, req)
+		assert.Equal(t, test.body, res.Body.String(), test.id)
+		assert.Equal(t, "HX-Request", res.Header().Get("Vary"), test.id)
+		assert.Equal(t, "text/html; charset=utf-8", res.Header().Get("Content-Type"), test.id)
+	}
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/missing", nil)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusInternalServerError, res.Code)
+
+	res = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/nav", nil)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, "<nav>2</nav>", res.Body.String())
+}
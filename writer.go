@@ -1,8 +1,12 @@
 package tigo
 
 import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"net/http"
+	"regexp"
 )
 
 // DataWriter is used by Context.Write() to write arbitrary data into an HTTP response.
@@ -37,3 +41,73 @@ func (w *dataWriter) Write(res http.ResponseWriter, data interface{}) error {
 	_, err := res.Write(bytes)
 	return err
 }
+
+// SecureJSONPrefix is written before the data of Context.SecureJSON, so that JSON arrays cannot be
+// executed as scripts by other sites. You may modify this variable, e.g. to "while(1);".
+var SecureJSONPrefix = ")]}',\n"
+
+// JSONDataWriter writes the data as JSON.
+type JSONDataWriter struct {
+	Prefix string //written before the JSON data
+	Indent string //indentation of the JSON data, compact if empty
+}
+
+func (w *JSONDataWriter) SetHeader(res http.ResponseWriter) {
+	res.Header().Set("Content-Type", MIME_JSON+"; charset=utf-8")
+}
+
+func (w *JSONDataWriter) Write(res http.ResponseWriter, data interface{}) error {
+	buf := new(bytes.Buffer)
+	buf.WriteString(w.Prefix)
+	encoder := json.NewEncoder(buf)
+	encoder.SetIndent("", w.Indent)
+	if err := encoder.Encode(data); err != nil {
+		return err
+	}
+	_, err := res.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	return err
+}
+
+// JSONPDataWriter writes the data as JSON passed to a JavaScript callback.
+type JSONPDataWriter struct {
+	Callback string //name of the callback, which must be a valid JavaScript identifier path
+}
+
+func (w *JSONPDataWriter) SetHeader(res http.ResponseWriter) {
+	res.Header().Set("Content-Type", "application/javascript; charset=utf-8")
+	res.Header().Set("X-Content-Type-Options", "nosniff")
+}
+
+func (w *JSONPDataWriter) Write(res http.ResponseWriter, data interface{}) error {
+	if !jsonpCallbackRegexp.MatchString(w.Callback) {
+		return NewHTTPError(http.StatusBadRequest, "Invalid JSONP callback")
+	}
+	bytes, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(res, "/**/ typeof %s === 'function' && %s(%s);", w.Callback, w.Callback, bytes)
+	return err
+}
+
+var jsonpCallbackRegexp = regexp.MustCompile(`^[a-zA-Z_$][\w$]*(\.[a-zA-Z_$][\w$]*)*$`)
+
+// XMLDataWriter writes the data as an XML document.
+type XMLDataWriter struct {
+	Indent string //indentation of the XML elements, compact if empty
+}
+
+func (w *XMLDataWriter) SetHeader(res http.ResponseWriter) {
+	res.Header().Set("Content-Type", MIME_XML+"; charset=utf-8")
+}
+
+func (w *XMLDataWriter) Write(res http.ResponseWriter, data interface{}) error {
+	buf := bytes.NewBufferString(xml.Header)
+	encoder := xml.NewEncoder(buf)
+	encoder.Indent("", w.Indent)
+	if err := encoder.Encode(data); err != nil {
+		return err
+	}
+	_, err := buf.WriteTo(res)
+	return err
+}
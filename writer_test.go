@@ -34,3 +34,20 @@ func TestDefaultDataWriter(t *testing.T) {
 	assert.Nil(t, c.Write("abc"))
 	assert.Equal(t, "abc", res.Body.String())
 }
+
+func TestJSONDataWriter(t *testing.T) {
+	res := httptest.NewRecorder()
+	writer := &JSONDataWriter{}
+	writer.SetHeader(res)
+	assert.Nil(t, writer.Write(res, map[string]int{"a": 1}))
+	assert.Equal(t, "application/json; charset=utf-8", res.Header().Get("Content-Type"))
+	assert.Equal(t, `{"a":1}`, res.Body.String())
+
+	res = httptest.NewRecorder()
+	assert.NotNil(t, writer.Write(res, func() {}))
+	assert.Equal(t, "", res.Body.String())
+
+	res = httptest.NewRecorder()
+	assert.NotNil(t, (&JSONPDataWriter{Callback: "a;b"}).Write(res, 1))
+	assert.Equal(t, "", res.Body.String())
+}